@@ -0,0 +1,251 @@
+package pine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to an entry when the asynchronous
+// queue is full.
+type OverflowPolicy int8
+
+const (
+	// DropNewest discards the entry being logged.
+	DropNewest OverflowPolicy = iota
+	// DropOldest discards the oldest queued entry to make room.
+	DropOldest
+	// Block waits for free space up to AsyncOptions.BlockTimeout and then
+	// discards the entry being logged.
+	Block
+)
+
+const (
+	defaultQueueSize    = 1024
+	defaultBlockTimeout = time.Second
+	defaultCloseTimeout = 5 * time.Second
+)
+
+type AsyncOptions struct {
+	QueueSize int
+	Overflow  OverflowPolicy
+	// BlockTimeout defaults to one second.
+	BlockTimeout time.Duration
+	// CloseTimeout bounds how long Close delivers queued messages, five
+	// seconds by default. Messages still queued then are dropped.
+	CloseTimeout time.Duration
+}
+
+// asyncWriter queues messages in a ring buffer which is drained to out by a
+// background goroutine, so a slow or unreachable destination does not block
+// the logging goroutines.
+type asyncWriter struct {
+	dropped uint64
+	failed  uint64
+
+	out          io.WriteCloser
+	errOut       io.Writer
+	policy       OverflowPolicy
+	timeout      time.Duration
+	closeTimeout time.Duration
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   [][]byte
+	head    int
+	count   int
+	writing bool
+	closed  bool
+	done    chan struct{}
+}
+
+func newAsyncWriter(out io.WriteCloser, errOut io.Writer, opts AsyncOptions) *asyncWriter {
+	size := opts.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	if opts.BlockTimeout <= 0 {
+		opts.BlockTimeout = defaultBlockTimeout
+	}
+	if opts.CloseTimeout <= 0 {
+		opts.CloseTimeout = defaultCloseTimeout
+	}
+	w := &asyncWriter{
+		out:          out,
+		errOut:       errOut,
+		policy:       opts.Overflow,
+		timeout:      opts.BlockTimeout,
+		closeTimeout: opts.CloseTimeout,
+		queue:        make([][]byte, size),
+		done:         make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w
+}
+
+// Write copies p into the queue. It never returns an error for a dropped
+// message; drops are counted instead.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	msg := make([]byte, len(p))
+	copy(msg, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, fmt.Errorf("writer is closed")
+	}
+
+	if w.count == len(w.queue) {
+		switch w.policy {
+		case DropOldest:
+			w.queue[w.head] = nil
+			w.head = (w.head + 1) % len(w.queue)
+			w.count--
+			atomic.AddUint64(&w.dropped, 1)
+		case Block:
+			if !w.waitForSpace() {
+				atomic.AddUint64(&w.dropped, 1)
+				return len(p), nil
+			}
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+			return len(p), nil
+		}
+	}
+
+	w.queue[(w.head+w.count)%len(w.queue)] = msg
+	w.count++
+	w.cond.Broadcast()
+	return len(p), nil
+}
+
+// waitForSpace must be called with w.mu held.
+func (w *asyncWriter) waitForSpace() bool {
+	timedOut := false
+	timer := time.AfterFunc(w.timeout, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		timedOut = true
+		w.cond.Broadcast()
+	})
+	defer timer.Stop()
+	for w.count == len(w.queue) && !w.closed && !timedOut {
+		w.cond.Wait()
+	}
+	return w.count < len(w.queue) && !w.closed
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for {
+		w.mu.Lock()
+		for w.count == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.count == 0 {
+			w.mu.Unlock()
+			return
+		}
+		msg := w.queue[w.head]
+		w.queue[w.head] = nil
+		w.head = (w.head + 1) % len(w.queue)
+		w.count--
+		w.writing = true
+		w.cond.Broadcast()
+		w.mu.Unlock()
+
+		if _, err := w.out.Write(msg); err != nil {
+			atomic.AddUint64(&w.failed, 1)
+			if w.errOut != nil {
+				fmt.Fprintf(w.errOut, "%v async write error: %v\n", time.Now(), err)
+			}
+		}
+
+		w.mu.Lock()
+		w.writing = false
+		w.cond.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+// Sync waits until every queued message has been written or ctx is done.
+func (w *asyncWriter) Sync(ctx context.Context) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.mu.Lock()
+			w.cond.Broadcast()
+			w.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for (w.count > 0 || w.writing) && ctx.Err() == nil {
+		w.cond.Wait()
+	}
+	if w.count > 0 || w.writing {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Close delivers the queued messages for up to the close timeout and closes
+// the underlying writer.
+func (w *asyncWriter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.closeTimeout)
+	defer cancel()
+	return w.closeContext(ctx)
+}
+
+// closeContext delivers the queued messages until ctx is done. The remaining
+// ones are dropped and the underlying writer is closed once the message being
+// written, which may be retried, is done.
+func (w *asyncWriter) closeContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return w.out.Close()
+	case <-ctx.Done():
+	}
+
+	w.mu.Lock()
+	dropped := w.count
+	for w.count > 0 {
+		w.queue[w.head] = nil
+		w.head = (w.head + 1) % len(w.queue)
+		w.count--
+	}
+	atomic.AddUint64(&w.dropped, uint64(dropped))
+	w.mu.Unlock()
+
+	go func() {
+		<-w.done
+		_ = w.out.Close()
+	}()
+	return fmt.Errorf("%d queued messages dropped: %w", dropped, ctx.Err())
+}
+
+func (w *asyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+func (w *asyncWriter) Failed() uint64 {
+	return atomic.LoadUint64(&w.failed)
+}
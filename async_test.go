@@ -0,0 +1,205 @@
+package pine
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingWriter struct {
+	mu       sync.Mutex
+	started  chan struct{}
+	release  chan struct{}
+	messages []string
+	closed   bool
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{started: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.started <- struct{}{}
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, string(p))
+	return len(p), nil
+}
+
+func (w *blockingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *blockingWriter) Messages() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string{}, w.messages...)
+}
+
+func TestAsyncWriter_DropNewest(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, nil, AsyncOptions{QueueSize: 2, Overflow: DropNewest})
+
+	_, _ = w.Write([]byte("1"))
+	<-out.started
+	for _, m := range []string{"2", "3", "4", "5"} {
+		_, err := w.Write([]byte(m))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint64(2), w.Dropped())
+
+	close(out.release)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"1", "2", "3"}, out.Messages())
+	assert.True(t, out.closed)
+}
+
+func TestAsyncWriter_DropOldest(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, nil, AsyncOptions{QueueSize: 2, Overflow: DropOldest})
+
+	_, _ = w.Write([]byte("1"))
+	<-out.started
+	for _, m := range []string{"2", "3", "4", "5"} {
+		_, _ = w.Write([]byte(m))
+	}
+	assert.Equal(t, uint64(2), w.Dropped())
+
+	close(out.release)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"1", "4", "5"}, out.Messages())
+}
+
+func TestAsyncWriter_BlockTimeout(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, nil, AsyncOptions{QueueSize: 1, Overflow: Block, BlockTimeout: time.Millisecond * 20})
+
+	_, _ = w.Write([]byte("1"))
+	<-out.started
+	_, _ = w.Write([]byte("2"))
+
+	start := time.Now()
+	_, _ = w.Write([]byte("3"))
+	assert.True(t, time.Since(start) >= time.Millisecond*20)
+	assert.Equal(t, uint64(1), w.Dropped())
+
+	close(out.release)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"1", "2"}, out.Messages())
+}
+
+func TestAsyncWriter_Block(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, nil, AsyncOptions{QueueSize: 1, Overflow: Block})
+
+	_, _ = w.Write([]byte("1"))
+	<-out.started
+	_, _ = w.Write([]byte("2"))
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("3"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("write should block while the queue is full")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	close(out.release)
+	<-written
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"1", "2", "3"}, out.Messages())
+	assert.Equal(t, uint64(0), w.Dropped())
+}
+
+func TestAsyncWriter_Sync(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, nil, AsyncOptions{})
+
+	_, _ = w.Write([]byte("1"))
+	<-out.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, w.Sync(ctx))
+
+	close(out.release)
+	assert.NoError(t, w.Sync(context.Background()))
+	assert.Equal(t, []string{"1"}, out.Messages())
+	require.NoError(t, w.Close())
+}
+
+func TestLogger_GraylogAsync(t *testing.T) {
+	os.Clearenv()
+	server := newServer(t)
+
+	lgr := New(Output(&bytes.Buffer{}), WithClock(newTestClock()), Graylog(server.Addr()), GraylogAsync(AsyncOptions{QueueSize: 10}))
+	for i := 0; i < 5; i++ {
+		lgr.Info("hello")
+	}
+	require.NoError(t, lgr.Sync(context.Background()))
+	lgr.Close()
+	require.NoError(t, server.Close())
+
+	assert.Equal(t, 5, len(server.messages))
+	assert.Equal(t, Stats{}, lgr.Stats())
+}
+
+func TestAsyncWriter_Defaults(t *testing.T) {
+	w := newAsyncWriter(newBlockingWriter(), nil, AsyncOptions{Overflow: Block})
+	assert.Equal(t, defaultBlockTimeout, w.timeout)
+	assert.Equal(t, defaultCloseTimeout, w.closeTimeout)
+	require.NoError(t, w.Close())
+}
+
+func TestAsyncWriter_CloseTimeout(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, nil, AsyncOptions{QueueSize: 10, CloseTimeout: time.Millisecond * 20})
+
+	_, _ = w.Write([]byte("1"))
+	<-out.started
+	_, _ = w.Write([]byte("2"))
+	_, _ = w.Write([]byte("3"))
+
+	start := time.Now()
+	err := w.Close()
+	assert.True(t, time.Since(start) < time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 queued messages dropped")
+	assert.Equal(t, uint64(2), w.Dropped())
+
+	close(out.release)
+	<-w.done
+	assert.Equal(t, []string{"1"}, out.Messages())
+}
+
+func TestLogger_CloseFlushTimeout(t *testing.T) {
+	os.Clearenv()
+	out := newBlockingWriter()
+	errOut := &bytes.Buffer{}
+	lgr := New(Output(&bytes.Buffer{}), ErrOutput(errOut), WithFlushTimeout(time.Millisecond*20))
+	lgr.handlers = append(lgr.handlers, &gelfHandler{out: newAsyncWriter(out, nil, AsyncOptions{QueueSize: 10})})
+
+	_, _ = lgr.handlers[1].(*gelfHandler).out.Write([]byte("1"))
+	<-out.started
+	_, _ = lgr.handlers[1].(*gelfHandler).out.Write([]byte("2"))
+
+	start := time.Now()
+	lgr.Close()
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Contains(t, errOut.String(), "1 queued messages dropped")
+	close(out.release)
+}
@@ -9,6 +9,8 @@ var getCaller = func(skip int) (pc uintptr, file string, line int, ok bool) {
 type Caller struct {
 	File string
 	Line int
+
+	pc uintptr
 }
 
 func shortFile(file string) string {
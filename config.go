@@ -0,0 +1,631 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-pckg/pine/gelf"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a declarative logger configuration, usually loaded from a JSON
+// or YAML file with LoadConfig. Levels are written like "info", durations
+// like "10s".
+type Config struct {
+	// Level is the default level of the outputs followed by the levels of
+	// named loggers, e.g. "info,api.billing=debug". Defaults to debug.
+	Level string `json:"level" yaml:"level"`
+	// StackTraceLevel is the most verbose level logging error stack traces.
+	// Defaults to error.
+	StackTraceLevel string `json:"stack_trace_level" yaml:"stack_trace_level"`
+	Caller          bool   `json:"caller" yaml:"caller"`
+	// Fields are added to every entry.
+	Fields map[string]interface{} `json:"fields" yaml:"fields"`
+
+	Console *ConsoleConfig `json:"console" yaml:"console"`
+	Graylog *GraylogConfig `json:"graylog" yaml:"graylog"`
+	File    *FileConfig    `json:"file" yaml:"file"`
+	Redact  *RedactConfig  `json:"redact" yaml:"redact"`
+}
+
+type ConsoleConfig struct {
+	Disabled bool   `json:"disabled" yaml:"disabled"`
+	Level    string `json:"level" yaml:"level"`
+	Format   string `json:"format" yaml:"format"`
+	// Output is stderr (default) or stdout.
+	Output   string          `json:"output" yaml:"output"`
+	Colors   bool            `json:"colors" yaml:"colors"`
+	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
+}
+
+type GraylogConfig struct {
+	// Addr is host:port with an optional udp://, tcp:// or tls:// scheme.
+	Addr  string `json:"addr" yaml:"addr"`
+	Level string `json:"level" yaml:"level"`
+	// Compression of the UDP transport: gzip (default), zlib or none.
+	Compression string            `json:"compression" yaml:"compression"`
+	ChunkSize   int               `json:"chunk_size" yaml:"chunk_size"`
+	Async       bool              `json:"async" yaml:"async"`
+	ExtraFields map[string]string `json:"extra_fields" yaml:"extra_fields"`
+	TLS         *TLSFilesConfig   `json:"tls" yaml:"tls"`
+	Sampling    *SamplingConfig   `json:"sampling" yaml:"sampling"`
+}
+
+// TLSFilesConfig holds PEM files, like the PINE_GRAYLOG_TLS_* variables.
+type TLSFilesConfig struct {
+	CA         string `json:"ca" yaml:"ca"`
+	Cert       string `json:"cert" yaml:"cert"`
+	Key        string `json:"key" yaml:"key"`
+	ServerName string `json:"server_name" yaml:"server_name"`
+}
+
+type FileConfig struct {
+	Path           string          `json:"path" yaml:"path"`
+	Level          string          `json:"level" yaml:"level"`
+	Format         string          `json:"format" yaml:"format"`
+	MaxSize        int64           `json:"max_size" yaml:"max_size"`
+	Interval       string          `json:"interval" yaml:"interval"`
+	MaxBackups     int             `json:"max_backups" yaml:"max_backups"`
+	MaxAge         string          `json:"max_age" yaml:"max_age"`
+	Compress       bool            `json:"compress" yaml:"compress"`
+	ReopenOnSIGHUP bool            `json:"reopen_on_sighup" yaml:"reopen_on_sighup"`
+	Sampling       *SamplingConfig `json:"sampling" yaml:"sampling"`
+}
+
+type RedactConfig struct {
+	Keys []string `json:"keys" yaml:"keys"`
+	// Detectors are card_number, email, jwt and aws_key.
+	Detectors []string `json:"detectors" yaml:"detectors"`
+	// Mask is full (default), partial, keeping Visible characters, or hmac
+	// with HMACKey.
+	Mask    string `json:"mask" yaml:"mask"`
+	Visible int    `json:"visible" yaml:"visible"`
+	HMACKey string `json:"hmac_key" yaml:"hmac_key"`
+}
+
+// SamplingConfig applies the same rule to every level, see SampleAll.
+type SamplingConfig struct {
+	Tick       string `json:"tick" yaml:"tick"`
+	First      int    `json:"first" yaml:"first"`
+	Thereafter int    `json:"thereafter" yaml:"thereafter"`
+}
+
+// ConfigError reports an invalid value of a config key.
+type ConfigError struct {
+	// Key is the dotted path of the key, e.g. graylog.level.
+	Key string
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("config: %s: %v", e.Key, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// LoadConfig reads a config from a .json, .yaml or .yml file. Unknown keys
+// are rejected.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(data, filepath.Ext(path))
+}
+
+func parseConfig(data []byte, ext string) (*Config, error) {
+	cfg := &Config{}
+	switch strings.ToLower(ext) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		dec.UseNumber()
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// an empty document leaves the defaults
+		if err := dec.Decode(cfg); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("config: unsupported file extension %q", ext)
+	}
+	return cfg, nil
+}
+
+// resolvedConfig holds the parsed values of a Config.
+type resolvedConfig struct {
+	level      Level
+	named      map[string]Level
+	stackLevel Level
+	console    Level
+	graylog    Level
+	file       Level
+	fields     []Field
+	options    []Option
+}
+
+// Validate reports the first invalid key of the config as a *ConfigError.
+func (c *Config) Validate() error {
+	_, err := c.resolve()
+	return err
+}
+
+func (c *Config) resolve() (*resolvedConfig, error) {
+	r := &resolvedConfig{level: DebugLevel, stackLevel: ErrorLevel}
+
+	def, hasDef, named, err := ParseLevels(c.Level)
+	if err != nil {
+		return nil, &ConfigError{Key: "level", Err: err}
+	}
+	if hasDef {
+		r.level = def
+	}
+	r.named = named
+	if r.stackLevel, err = configLevel("stack_trace_level", c.StackTraceLevel, ErrorLevel); err != nil {
+		return nil, err
+	}
+	r.console, r.graylog, r.file = r.level, r.level, r.level
+
+	if c.Caller {
+		r.options = append(r.options, AddCaller())
+	}
+	keys := make([]string, 0, len(c.Fields))
+	for key := range c.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		f, err := configField(key, c.Fields[key])
+		if err != nil {
+			return nil, &ConfigError{Key: "fields." + key, Err: err}
+		}
+		r.fields = append(r.fields, f)
+	}
+
+	steps := []func(r *resolvedConfig) error{c.resolveConsole, c.resolveGraylog, c.resolveFile, c.resolveRedact}
+	for _, step := range steps {
+		if err := step(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (c *Config) resolveConsole(r *resolvedConfig) error {
+	cc := c.Console
+	if cc == nil {
+		return nil
+	}
+	if cc.Disabled {
+		r.options = append(r.options, NoConsole())
+		return nil
+	}
+
+	var err error
+	if r.console, err = configLevel("console.level", cc.Level, r.level); err != nil {
+		return err
+	}
+	format, err := ParseFormat(cc.Format)
+	if err != nil {
+		return &ConfigError{Key: "console.format", Err: err}
+	}
+	r.options = append(r.options, WithFormat(format), Colored(cc.Colors))
+
+	switch strings.ToLower(cc.Output) {
+	case "", "stderr":
+		r.options = append(r.options, Output(os.Stderr))
+	case "stdout":
+		r.options = append(r.options, Output(os.Stdout))
+	default:
+		return &ConfigError{Key: "console.output", Err: fmt.Errorf("invalid output: %q", cc.Output)}
+	}
+
+	if cc.Sampling != nil {
+		opts, err := cc.Sampling.options("console.sampling")
+		if err != nil {
+			return err
+		}
+		r.options = append(r.options, Sampling(opts))
+	}
+	return nil
+}
+
+func (c *Config) resolveGraylog(r *resolvedConfig) error {
+	gc := c.Graylog
+	if gc == nil {
+		return nil
+	}
+	if gc.Addr == "" {
+		return &ConfigError{Key: "graylog.addr", Err: fmt.Errorf("missing address")}
+	}
+
+	var err error
+	if r.graylog, err = configLevel("graylog.level", gc.Level, r.level); err != nil {
+		return err
+	}
+	r.options = append(r.options, Graylog(gc.Addr))
+
+	switch strings.ToLower(gc.Compression) {
+	case "", "gzip":
+		r.options = append(r.options, GraylogCompression(gelf.CompressGzip))
+	case "zlib":
+		r.options = append(r.options, GraylogCompression(gelf.CompressZlib))
+	case "none":
+		r.options = append(r.options, GraylogCompression(gelf.CompressNone))
+	default:
+		return &ConfigError{Key: "graylog.compression", Err: fmt.Errorf("invalid compression: %q", gc.Compression)}
+	}
+
+	if gc.ChunkSize < 0 {
+		return &ConfigError{Key: "graylog.chunk_size", Err: fmt.Errorf("negative size %d", gc.ChunkSize)}
+	}
+	if gc.ChunkSize > 0 {
+		r.options = append(r.options, GraylogChunkSize(gc.ChunkSize))
+	}
+	if gc.Async {
+		r.options = append(r.options, GraylogAsync(AsyncOptions{}))
+	}
+	if len(gc.ExtraFields) > 0 {
+		extra := gc.ExtraFields
+		r.options = append(r.options, optionFunc(func(c *config) {
+			for k, v := range extra {
+				c.gelfConfig.ExtraFields[k] = String(k, v)
+			}
+		}))
+	}
+	if gc.TLS != nil {
+		tlsCfg, err := newTLSConfig(gc.TLS.CA, gc.TLS.Cert, gc.TLS.Key, gc.TLS.ServerName)
+		if err != nil {
+			return &ConfigError{Key: "graylog.tls", Err: err}
+		}
+		r.options = append(r.options, GraylogTLS(tlsCfg))
+	}
+	if gc.Sampling != nil {
+		opts, err := gc.Sampling.options("graylog.sampling")
+		if err != nil {
+			return err
+		}
+		r.options = append(r.options, GraylogSampling(opts))
+	}
+	return nil
+}
+
+func (c *Config) resolveFile(r *resolvedConfig) error {
+	fc := c.File
+	if fc == nil {
+		return nil
+	}
+	if fc.Path == "" {
+		return &ConfigError{Key: "file.path", Err: fmt.Errorf("missing path")}
+	}
+
+	var err error
+	if r.file, err = configLevel("file.level", fc.Level, r.level); err != nil {
+		return err
+	}
+	format, err := ParseFormat(fc.Format)
+	if err != nil {
+		return &ConfigError{Key: "file.format", Err: err}
+	}
+
+	opts := RotateOptions{
+		MaxSize:        fc.MaxSize,
+		MaxBackups:     fc.MaxBackups,
+		Compress:       fc.Compress,
+		ReopenOnSIGHUP: fc.ReopenOnSIGHUP,
+	}
+	if opts.Interval, err = configDuration("file.interval", fc.Interval); err != nil {
+		return err
+	}
+	if opts.MaxAge, err = configDuration("file.max_age", fc.MaxAge); err != nil {
+		return err
+	}
+	r.options = append(r.options, File(fc.Path, opts), FileFormat(format))
+
+	if fc.Sampling != nil {
+		opts, err := fc.Sampling.options("file.sampling")
+		if err != nil {
+			return err
+		}
+		r.options = append(r.options, FileSampling(opts))
+	}
+	return nil
+}
+
+func (c *Config) resolveRedact(r *resolvedConfig) error {
+	rc := c.Redact
+	if rc == nil {
+		return nil
+	}
+
+	opts := RedactOptions{Keys: rc.Keys}
+	for _, name := range rc.Detectors {
+		found := false
+		for _, d := range DefaultDetectors() {
+			if d.Name == name {
+				opts.Detectors = append(opts.Detectors, d)
+				found = true
+			}
+		}
+		if !found {
+			return &ConfigError{Key: "redact.detectors", Err: fmt.Errorf("unknown detector %q", name)}
+		}
+	}
+
+	switch strings.ToLower(rc.Mask) {
+	case "", "full":
+		opts.Mask = MaskFull()
+	case "partial":
+		opts.Mask = MaskPartial(rc.Visible)
+	case "hmac":
+		if rc.HMACKey == "" {
+			return &ConfigError{Key: "redact.hmac_key", Err: fmt.Errorf("missing key for the hmac mask")}
+		}
+		opts.Mask = MaskHMAC([]byte(rc.HMACKey))
+	default:
+		return &ConfigError{Key: "redact.mask", Err: fmt.Errorf("invalid mask: %q", rc.Mask)}
+	}
+	r.options = append(r.options, Redact(opts))
+	return nil
+}
+
+func (s *SamplingConfig) options(key string) (SamplingOptions, error) {
+	tick, err := configDuration(key+".tick", s.Tick)
+	if err != nil {
+		return SamplingOptions{}, err
+	}
+	if tick <= 0 {
+		return SamplingOptions{}, &ConfigError{Key: key + ".tick", Err: fmt.Errorf("missing tick")}
+	}
+	return SampleAll(tick, s.First, s.Thereafter), nil
+}
+
+func configLevel(key, text string, def Level) (Level, error) {
+	if text == "" {
+		return def, nil
+	}
+	lvl, err := ParseLevel(text)
+	if err != nil {
+		return def, &ConfigError{Key: key, Err: err}
+	}
+	return lvl, nil
+}
+
+func configDuration(key, text string) (time.Duration, error) {
+	if text == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, &ConfigError{Key: key, Err: err}
+	}
+	return d, nil
+}
+
+// configField converts a decoded JSON or YAML value to a field.
+func configField(key string, v interface{}) (Field, error) {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return Int64(key, i), nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return Field{}, err
+		}
+		return Float64(key, f), nil
+	case nil:
+		return Field{}, fmt.Errorf("missing value")
+	case map[string]interface{}, []interface{}:
+		return Json(key, t), nil
+	default:
+		return Any(key, t), nil
+	}
+}
+
+// NewFromConfig creates a logger from cfg. The options are applied after
+// the config, e.g. to add handlers or hooks. The levels and fields can be
+// changed later with ApplyConfig or WatchConfig.
+func NewFromConfig(cfg *Config, options ...Option) (*Logger, error) {
+	r, err := cfg.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &configState{
+		console:    NewNamedLevelValue("console", r.console),
+		graylog:    NewNamedLevelValue("graylog", r.graylog),
+		file:       NewNamedLevelValue("file", r.file),
+		stackLevel: NewLevelValue(r.stackLevel),
+		registry:   NewLevelRegistry(),
+		fields:     r.fields,
+	}
+	for prefix, lvl := range r.named {
+		state.registry.SetLevel(prefix, lvl)
+	}
+
+	opts := []Option{
+		WithLevelValue(state.console),
+		GraylogLevelValue(state.graylog),
+		FileLevelValue(state.file),
+		WithLevelRegistry(state.registry),
+		optionFunc(func(c *config) {
+			c.stackTraceLevel = state.stackLevel
+			if cfg.Graylog == nil || cfg.Graylog.Level == "" {
+				c.defaultLevels = append(c.defaultLevels, state.graylog)
+			}
+			if cfg.File == nil || cfg.File.Level == "" {
+				c.defaultLevels = append(c.defaultLevels, state.file)
+			}
+		}),
+		WithHook(state),
+	}
+	opts = append(opts, r.options...)
+	opts = append(opts, options...)
+	return New(opts...), nil
+}
+
+// ApplyConfig applies the levels and fields of cfg to a logger created with
+// NewFromConfig. Changes of the outputs require a new logger and are
+// ignored.
+func (l *Logger) ApplyConfig(cfg *Config) error {
+	var state *configState
+	for _, h := range l.hooks {
+		if s, ok := h.(*configState); ok {
+			state = s
+		}
+	}
+	if state == nil {
+		return fmt.Errorf("config: logger was not created with NewFromConfig")
+	}
+
+	r, err := cfg.resolve()
+	if err != nil {
+		return err
+	}
+	state.apply(r)
+	return nil
+}
+
+// configState holds the values of a logger which can change when the
+// config is reloaded. It adds the configured fields to the entries.
+type configState struct {
+	console    *LevelValue
+	graylog    *LevelValue
+	file       *LevelValue
+	stackLevel *LevelValue
+	registry   *LevelRegistry
+
+	mu     sync.RWMutex
+	fields []Field
+}
+
+func (s *configState) Levels() []Level {
+	return nil
+}
+
+func (s *configState) Fire(ent *Entry) bool {
+	s.mu.RLock()
+	fields := s.fields
+	s.mu.RUnlock()
+	if len(fields) > 0 {
+		ent.AddFields(fields...)
+	}
+	return true
+}
+
+func (s *configState) apply(r *resolvedConfig) {
+	s.console.SetLevel(r.console)
+	s.graylog.SetLevel(r.graylog)
+	s.file.SetLevel(r.file)
+	s.stackLevel.SetLevel(r.stackLevel)
+
+	for prefix := range s.registry.Levels() {
+		if _, ok := r.named[prefix]; !ok {
+			s.registry.Unset(prefix)
+		}
+	}
+	for prefix, lvl := range r.named {
+		s.registry.SetLevel(prefix, lvl)
+	}
+
+	s.mu.Lock()
+	s.fields = r.fields
+	s.mu.Unlock()
+}
+
+// ConfigWatcher reloads a config file when its content changes.
+type ConfigWatcher struct {
+	logger   *Logger
+	path     string
+	interval time.Duration
+	last     []byte
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// WatchConfig checks the config file at path every interval, one second by
+// default, and applies the levels and fields of a changed file to l, see
+// ApplyConfig. Reloads and invalid files are logged with l; an invalid file
+// leaves the logger unchanged.
+func WatchConfig(l *Logger, path string, interval time.Duration) (*ConfigWatcher, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &ConfigWatcher{
+		logger:   l,
+		path:     path,
+		interval: interval,
+		last:     data,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.reload()
+		}
+	}
+}
+
+func (w *ConfigWatcher) reload() {
+	data, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		// the file may be replaced right now, keep the current config
+		return
+	}
+	if bytes.Equal(data, w.last) {
+		return
+	}
+	w.last = data
+
+	cfg, err := parseConfig(data, filepath.Ext(w.path))
+	if err == nil {
+		err = w.logger.ApplyConfig(cfg)
+	}
+	if err != nil {
+		w.logger.Error("config reload failed", String("path", w.path), Err(err))
+		return
+	}
+	w.logger.Info("config reloaded", String("path", w.path))
+}
+
+// Close stops watching the file. It is safe to call more than once.
+func (w *ConfigWatcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+	return nil
+}
@@ -0,0 +1,179 @@
+package pine
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAMLConfig = `
+level: info,api.billing=debug
+fields:
+  service: api
+  replicas: 3
+console:
+  format: json
+graylog:
+  addr: udp://127.0.0.1:12201
+  level: warn
+  compression: none
+  sampling:
+    tick: 1s
+    first: 10
+redact:
+  keys: ["*password*"]
+  detectors: [email]
+  mask: partial
+  visible: 4
+`
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(testYAMLConfig), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "info,api.billing=debug", cfg.Level)
+	assert.Equal(t, map[string]interface{}{"service": "api", "replicas": 3}, cfg.Fields)
+	assert.Equal(t, "json", cfg.Console.Format)
+	assert.Equal(t, &SamplingConfig{Tick: "1s", First: 10}, cfg.Graylog.Sampling)
+	assert.Equal(t, []string{"email"}, cfg.Redact.Detectors)
+	assert.NoError(t, cfg.Validate())
+
+	cfg, err = parseConfig([]byte(`{"level":"warn","fields":{"replicas":3},"file":{"path":"app.log","max_age":"24h"}}`), ".json")
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.Level)
+	assert.Equal(t, "app.log", cfg.File.Path)
+	assert.NoError(t, cfg.Validate())
+
+	_, err = parseConfig([]byte("levle: info\n"), ".yaml")
+	assert.EqualError(t, err, "config: yaml: unmarshal errors:\n  line 1: field levle not found in type pine.Config")
+	_, err = parseConfig([]byte(`{"console":{"formt":"json"}}`), ".json")
+	assert.EqualError(t, err, `config: json: unknown field "formt"`)
+	_, err = parseConfig(nil, ".toml")
+	assert.EqualError(t, err, `config: unsupported file extension ".toml"`)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		cfg Config
+		err string
+		key string
+	}{
+		{Config{Level: "info,api=loud"}, `config: level: invalid level: "loud"`, "level"},
+		{Config{StackTraceLevel: "x"}, `config: stack_trace_level: invalid level: "x"`, "stack_trace_level"},
+		{Config{Console: &ConsoleConfig{Format: "xml"}}, `config: console.format: invalid format: "xml"`, "console.format"},
+		{Config{Console: &ConsoleConfig{Output: "syslog"}}, `config: console.output: invalid output: "syslog"`, "console.output"},
+		{Config{Graylog: &GraylogConfig{}}, `config: graylog.addr: missing address`, "graylog.addr"},
+		{Config{Graylog: &GraylogConfig{Addr: "g:12201", Level: "loud"}}, `config: graylog.level: invalid level: "loud"`, "graylog.level"},
+		{Config{Graylog: &GraylogConfig{Addr: "g:12201", Compression: "lz4"}}, `config: graylog.compression: invalid compression: "lz4"`, "graylog.compression"},
+		{Config{Graylog: &GraylogConfig{Addr: "g:12201", Sampling: &SamplingConfig{First: 1}}}, `config: graylog.sampling.tick: missing tick`, "graylog.sampling.tick"},
+		{Config{File: &FileConfig{Path: "app.log", MaxAge: "day"}}, `config: file.max_age: time: invalid duration "day"`, "file.max_age"},
+		{Config{Redact: &RedactConfig{Detectors: []string{"iban"}}}, `config: redact.detectors: unknown detector "iban"`, "redact.detectors"},
+		{Config{Redact: &RedactConfig{Mask: "hmac"}}, `config: redact.hmac_key: missing key for the hmac mask`, "redact.hmac_key"},
+		{Config{Fields: map[string]interface{}{"env": nil}}, `config: fields.env: missing value`, "fields.env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			assert.EqualError(t, err, tt.err)
+			var cfgErr *ConfigError
+			require.True(t, errors.As(err, &cfgErr))
+			assert.Equal(t, tt.key, cfgErr.Key)
+		})
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(testYAMLConfig), ".yaml")
+	require.NoError(t, err)
+	cfg.Graylog = nil
+
+	buf := &bytes.Buffer{}
+	lgr, err := NewFromConfig(cfg, Output(buf), WithClock(newTestClock()))
+	require.NoError(t, err)
+
+	lgr.Debug("hidden")
+	lgr.Named("api.billing").Debug("charged", String("email", "jane@example.com"), String("db_password", "secret"))
+	assert.Equal(t, `{"time":"2022-08-10T21:29:59.123Z","level":"debug","logger":"api.billing","message":"charged",`+
+		`"db_password":"**cret","email":"************.com","replicas":3,"service":"api"}`+"\n", buf.String())
+
+	_, err = NewFromConfig(&Config{Level: "loud"})
+	assert.EqualError(t, err, `config: level: invalid level: "loud"`)
+}
+
+func TestLogger_ApplyConfig(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr, err := NewFromConfig(&Config{Level: "info,db=debug", Fields: map[string]interface{}{"v": "1"}},
+		Output(buf), NoColors(), WithClock(newTestClock()))
+	require.NoError(t, err)
+	child := lgr.With(String("c", "x")).Named("api")
+
+	require.NoError(t, lgr.ApplyConfig(&Config{Level: "warn,api=debug", Fields: map[string]interface{}{"v": "2"}}))
+	lgr.Info("hidden")
+	lgr.Named("db").Debug("hidden")
+	child.Debug("shown")
+	assert.Equal(t, "2022-08-10T21:29:59.123Z DBG [api] shown c=x v=2\n", buf.String())
+	assert.Equal(t, map[string]Level{"api": DebugLevel}, lgr.LevelRegistry().Levels())
+
+	assert.EqualError(t, lgr.ApplyConfig(&Config{Level: "loud"}), `config: level: invalid level: "loud"`)
+	assert.EqualError(t, New().ApplyConfig(&Config{}), "config: logger was not created with NewFromConfig")
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pine.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("level: info\n"), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	buf := &syncBuffer{}
+	lgr, err := NewFromConfig(cfg, Output(buf), NoColors(), WithClock(newTestClock()))
+	require.NoError(t, err)
+
+	w, err := WatchConfig(lgr, path, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("level: loud\n"), 0o600))
+	assert.Eventually(t, func() bool {
+		return bytes.Contains(buf.Bytes(), []byte("config reload failed"))
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("level: debug\n"), 0o600))
+	assert.Eventually(t, func() bool {
+		return bytes.Contains(buf.Bytes(), []byte("config reloaded"))
+	}, time.Second, 5*time.Millisecond)
+	assert.True(t, lgr.isLevelEnabled(DebugLevel))
+}
+
+func TestConfigWatcher_CloseTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pine.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("level: info\n"), 0o600))
+	lgr := New(NoConsole())
+
+	w, err := WatchConfig(lgr, path, time.Hour)
+	require.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Close())
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
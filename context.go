@@ -0,0 +1,110 @@
+package pine
+
+import (
+	"context"
+	"sync"
+)
+
+type loggerContextKey struct{}
+
+type fieldsContextKey struct{}
+
+// ContextExtractor returns fields derived from a context, e.g. a request id
+// set by a middleware.
+type ContextExtractor func(ctx context.Context) []Field
+
+var (
+	defaultLogger     *Logger
+	defaultLoggerOnce sync.Once
+)
+
+// NewContext returns a copy of ctx carrying the logger.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by NewContext. When ctx carries
+// no logger a default logger created with New() is returned.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	defaultLoggerOnce.Do(func() {
+		defaultLogger = New()
+	})
+	return defaultLogger
+}
+
+// ContextWith returns a copy of ctx carrying the given fields in addition to
+// the fields already stored in ctx. The fields are added to every entry logged
+// with one of the *Ctx methods.
+func ContextWith(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	existing := ContextFields(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, fields...)
+	merged = append(merged, existing...)
+	return context.WithValue(ctx, fieldsContextKey{}, merged)
+}
+
+// ContextFields returns the fields stored in ctx by ContextWith.
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey{}).([]Field)
+	return fields
+}
+
+func (l *Logger) TraceCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(TraceLevel, msg, nil, l.contextFields(ctx, TraceLevel, fields))
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(DebugLevel, msg, nil, l.contextFields(ctx, DebugLevel, fields))
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(InfoLevel, msg, nil, l.contextFields(ctx, InfoLevel, fields))
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(WarnLevel, msg, nil, l.contextFields(ctx, WarnLevel, fields))
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(ErrorLevel, msg, nil, l.contextFields(ctx, ErrorLevel, fields))
+}
+
+func (l *Logger) PanicCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(PanicLevel, msg, nil, l.contextFields(ctx, PanicLevel, fields))
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(FatalLevel, msg, nil, l.contextFields(ctx, FatalLevel, fields))
+}
+
+// contextFields appends the fields stored in ctx and the fields returned by
+// the context extractors to the entry fields.
+func (l *Logger) contextFields(ctx context.Context, lvl Level, fields []Field) []Field {
+	if ctx == nil || !l.isLevelEnabled(lvl) {
+		return fields
+	}
+
+	ctxFields := ContextFields(ctx)
+	if len(ctxFields) == 0 && len(l.contextExtractors) == 0 {
+		return fields
+	}
+
+	merged := make([]Field, 0, len(fields)+len(ctxFields))
+	merged = append(merged, fields...)
+	merged = append(merged, ctxFields...)
+	for i := range l.contextExtractors {
+		merged = append(merged, l.contextExtractors[i](ctx)...)
+	}
+	return merged
+}
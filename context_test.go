@@ -0,0 +1,49 @@
+package pine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+func TestContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(Output(buf), WithClock(newTestClock()), WithContextExtractor(func(ctx context.Context) []Field {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []Field{String("request_id", id)}
+		}
+		return nil
+	}))
+
+	ctx := NewContext(context.Background(), lgr)
+	ctx = context.WithValue(ctx, requestIDKey{}, "r1")
+	ctx = ContextWith(ctx, String("user", "u1"))
+	ctx = ContextWith(ctx, Int("attempt", 2))
+
+	t.Run("from context", func(tt *testing.T) {
+		assert.Same(tt, lgr, FromContext(ctx))
+		assert.NotNil(tt, FromContext(context.Background()))
+	})
+
+	t.Run("ctx fields", func(tt *testing.T) {
+		FromContext(ctx).InfoCtx(ctx, "hello", String("A", "B"))
+		assert.Equal(tt, "2022-08-10T21:29:59.123Z INF hello A=B attempt=2 request_id=r1 user=u1\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("plain context", func(tt *testing.T) {
+		lgr.WarnCtx(context.Background(), "hello")
+		assert.Equal(tt, "2022-08-10T21:29:59.123Z WRN hello\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("with", func(tt *testing.T) {
+		lgr.With(Int("i", 1)).ErrorCtx(ctx, "hello")
+		assert.Equal(tt, "2022-08-10T21:29:59.123Z ERR hello attempt=2 i=1 request_id=r1 user=u1\n", buf.String())
+		buf.Reset()
+	})
+}
@@ -56,11 +56,20 @@ type encoderConfig struct {
 	DisableQuote     bool
 	ReportCaller     bool
 	DisableSorting   bool
+	JSONKeys         JSONKeys
 }
 
 type encoder interface {
 	encodeEntry(ent *Entry, fields []Field) ([]byte, error)
-	clone() encoder
+}
+
+func newEncoder(format Format, config encoderConfig) encoder {
+	switch format {
+	case JSONFormat:
+		return newJSONEncoder(config)
+	default:
+		return newConsoleEncoder(config)
+	}
 }
 
 type consoleEncoder struct {
@@ -71,10 +80,6 @@ func newConsoleEncoder(config encoderConfig) consoleEncoder {
 	return consoleEncoder{encoderConfig: &config}
 }
 
-func (l consoleEncoder) clone() encoder {
-	return newConsoleEncoder(*l.encoderConfig)
-}
-
 func (l consoleEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
 	lvl := consoleLevel(ent.level)
 	switch ent.level {
@@ -102,6 +107,9 @@ func (l consoleEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error)
 	if l.ReportCaller && ent.caller != nil {
 		entities = append(entities, fmt.Sprintf("%s:%v", ent.caller.File, ent.caller.Line))
 	}
+	if ent.name != "" {
+		entities = append(entities, colorize("["+ent.name+"]", colorBold, l.UseColors))
+	}
 	entities = append(entities, ent.message)
 
 	buf := newBuffer()
@@ -120,9 +128,8 @@ func (l consoleEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error)
 		}
 	}
 
-	if ent.stack != nil {
-		stackTrace := flattenStack(ent.stack)
-		fields = append(fields, String("stack", stackTrace))
+	for i, st := range ent.stacks {
+		fields = append(fields, String(stackKey("stack", i), flattenStack(st)))
 	}
 
 	fieldsMap := map[string][]Field{}
@@ -154,6 +161,17 @@ func (l consoleEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error)
 }
 
 func (l consoleEncoder) appendField(b *bytes.Buffer, field Field) error {
+	if field.tp == objectType || field.tp == arrayType {
+		v, err := marshalField(field)
+		if err != nil {
+			return err
+		}
+		flattenValue(field.key, ".", v, func(key string, leaf interface{}) {
+			l.appendKeyValue(b, key, leafString(leaf), colorCyan)
+		})
+		return nil
+	}
+
 	ok, value, err := getStringValue(field)
 	if err != nil {
 		return err
@@ -168,14 +186,18 @@ func (l consoleEncoder) appendField(b *bytes.Buffer, field Field) error {
 		keyColour = colorRed
 	}
 
+	l.appendKeyValue(b, field.key, value, keyColour)
+
+	return nil
+}
+
+func (l consoleEncoder) appendKeyValue(b *bytes.Buffer, key, value string, keyColour int) {
 	if b.Len() > 0 {
 		b.WriteByte(' ')
 	}
-	b.WriteString(colorize(field.key, keyColour, l.UseColors))
+	b.WriteString(colorize(key, keyColour, l.UseColors))
 	b.WriteByte('=')
 	l.appendValue(b, value)
-
-	return nil
 }
 
 func (l consoleEncoder) appendValue(b *bytes.Buffer, value string) {
@@ -209,14 +231,12 @@ func (l consoleEncoder) needsQuoting(text string) bool {
 
 type gelfEncoder struct {
 	extraFields map[string]Field
+	opts        GelfFieldOptions
+	msgOpts     GelfMessageOptions
 }
 
-func newGelfEncoder(extraFields map[string]Field) gelfEncoder {
-	return gelfEncoder{extraFields: extraFields}
-}
-
-func (l gelfEncoder) clone() encoder {
-	return newGelfEncoder(l.extraFields)
+func newGelfEncoder(extraFields map[string]Field, opts GelfFieldOptions, msgOpts GelfMessageOptions) gelfEncoder {
+	return gelfEncoder{extraFields: extraFields, opts: opts, msgOpts: msgOpts}
 }
 
 func (l gelfEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
@@ -238,9 +258,10 @@ func (l gelfEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
 	defer gelfPool.Put(gelfMsg)
 	gelfMsg.Version = "1.1"
 	gelfMsg.Level = gelfLevel(ent.level)
-	gelfMsg.TimeUnix = float64(ent.time.Unix())
-	gelfMsg.Short = ent.message
-	gelfMsg.Full = ""
+	short, cut := l.msgOpts.shortMessage(ent.message)
+	gelfMsg.TimeUnix = l.msgOpts.timestamp(ent.time)
+	gelfMsg.Short = short
+	gelfMsg.Full = l.msgOpts.fullMessage(ent, fields, cut)
 	gelfMsg.Host = hostname
 	gelfMsg.Extra = map[string]interface{}{}
 
@@ -249,6 +270,9 @@ func (l gelfEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
 		gelfMsg.Extra["_file"] = ent.caller.File
 		gelfMsg.Extra["_line"] = ent.caller.Line
 	}
+	if ent.name != "" {
+		gelfMsg.Extra["_logger"] = ent.name
+	}
 
 	for i := range l.extraFields {
 		if l.extraFields[i].key == "host" {
@@ -268,8 +292,10 @@ func (l gelfEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
 		}
 	}
 
-	if ent.stack != nil {
-		gelfMsg.Extra["_stack"] = fmt.Sprintf("%+v", ent.stack)
+	if l.msgOpts.FullMessage&FullMessageStack == 0 {
+		for i, st := range ent.stacks {
+			gelfMsg.Extra[gelfKey(stackKey("stack", i))] = st.String()
+		}
 	}
 
 	buf := newBuffer()
@@ -281,6 +307,38 @@ func (l gelfEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
 }
 
 func (l gelfEncoder) appendField(m map[string]interface{}, field Field) error {
+	key := gelfFieldKey(field)
+	switch field.tp {
+	case intType, int8Type, int16Type, int32Type, int64Type:
+		m[gelfKey(key)] = field.int64
+	case float32Type, float64Type:
+		l.appendValue(m, key, field.float64)
+	case boolType:
+		l.appendValue(m, key, field.int64 == 1)
+	case timeType:
+		l.appendValue(m, key, field.value.(time.Time))
+	case objectType, arrayType:
+		v, err := marshalField(field)
+		if err != nil {
+			return err
+		}
+		l.appendValue(m, key, v)
+	case jsonType:
+		if l.opts.Mapping == GelfStringify {
+			return l.appendString(m, key, field)
+		}
+		v, err := jsonTreeValue(field.value)
+		if err != nil {
+			return err
+		}
+		l.appendValue(m, key, v)
+	default:
+		return l.appendString(m, key, field)
+	}
+	return nil
+}
+
+func (l gelfEncoder) appendString(m map[string]interface{}, key string, field Field) error {
 	ok, value, err := getStringValue(field)
 	if err != nil {
 		return err
@@ -289,7 +347,7 @@ func (l gelfEncoder) appendField(m map[string]interface{}, field Field) error {
 		return nil
 	}
 
-	m["_"+field.key] = value
+	m[gelfKey(key)] = value
 
 	return nil
 }
@@ -373,6 +431,16 @@ func getStringValue(field Field) (bool, string, error) {
 		value = string(bts)
 	case interfaceType:
 		value = fmt.Sprint(field.value)
+	case objectType, arrayType:
+		v, err := marshalField(field)
+		if err != nil {
+			return false, "", err
+		}
+		bts, err := json.Marshal(v)
+		if err != nil {
+			return false, "", err
+		}
+		value = string(bts)
 	case errorType:
 		if field.err == nil {
 			return false, "", nil
@@ -3,8 +3,6 @@ package pine
 import (
 	"sync"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 var entryPool = &sync.Pool{
@@ -15,14 +13,87 @@ var entryPool = &sync.Pool{
 
 type Entry struct {
 	logger  *Logger
+	name    string
 	level   Level
 	time    time.Time
 	message string
 	caller  *Caller
-	stack   errors.StackTrace
+	stacks  []Stack
 	fields  []Field
 }
 
+// Level returns the level of the entry.
+func (e *Entry) Level() Level {
+	return e.level
+}
+
+// Time returns the time the entry was logged at.
+func (e *Entry) Time() time.Time {
+	return e.time
+}
+
+// LoggerName returns the name of the logger the entry was logged with.
+func (e *Entry) LoggerName() string {
+	return e.name
+}
+
+// Message returns the formatted message of the entry.
+func (e *Entry) Message() string {
+	return e.message
+}
+
+// Caller returns the location the entry was logged from, or nil when it is
+// unknown.
+func (e *Entry) Caller() *Caller {
+	return e.caller
+}
+
+// Stack returns the stack trace of the logged error, or nil.
+func (e *Entry) Stack() Stack {
+	if len(e.stacks) == 0 {
+		return nil
+	}
+	return e.stacks[0]
+}
+
+// Stacks returns the stack traces of the logged errors, one for every branch
+// of joined errors.
+func (e *Entry) Stacks() []Stack {
+	return e.stacks
+}
+
+// Fields returns the entry fields followed by the logger fields. The slice
+// must not be modified.
+func (e *Entry) Fields() []Field {
+	return e.fields
+}
+
+// SetMessage replaces the message of the entry.
+func (e *Entry) SetMessage(msg string) {
+	e.message = msg
+}
+
+// SetFields replaces the fields of the entry.
+func (e *Entry) SetFields(fields ...Field) {
+	e.fields = fields
+}
+
+// AddFields appends fields to the entry.
+func (e *Entry) AddFields(fields ...Field) {
+	e.fields = append(e.fields[:len(e.fields):len(e.fields)], fields...)
+}
+
+// RemoveField removes all fields with the key from the entry.
+func (e *Entry) RemoveField(key string) {
+	fields := make([]Field, 0, len(e.fields))
+	for i := range e.fields {
+		if e.fields[i].key != key {
+			fields = append(fields, e.fields[i])
+		}
+	}
+	e.fields = fields
+}
+
 func (e *Entry) Debugf(msg string, args ...interface{}) {
 	e.logger.log(DebugLevel, msg, args, e.fields)
 }
@@ -39,20 +110,22 @@ func (e *Entry) Errorf(msg string, args ...interface{}) {
 	e.logger.log(ErrorLevel, msg, args, e.fields)
 }
 
+// Panicf logs the message at PanicLevel and panics, see Logger.Panic.
 func (e *Entry) Panicf(msg string, args ...interface{}) {
 	e.logger.log(PanicLevel, msg, args, e.fields)
 }
 
+// Fatalf logs the message at FatalLevel and exits, see Logger.Fatal.
 func (e *Entry) Fatalf(msg string, args ...interface{}) {
 	e.logger.log(FatalLevel, msg, args, e.fields)
 	entryPool.Put(e)
 }
 
 func (e *Entry) logCaller(skipFrame int) {
-	_, file, line, ok := getCaller(skipFrame)
+	pc, file, line, ok := getCaller(skipFrame)
 	if !ok {
 		e.caller = nil
 		return
 	}
-	e.caller = &Caller{File: shortFile(file), Line: line}
+	e.caller = &Caller{File: shortFile(file), Line: line, pc: pc}
 }
@@ -0,0 +1,123 @@
+package pine
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ErrorFielder is implemented by errors carrying structured attributes,
+// e.g. an order id or an error code.
+type ErrorFielder interface {
+	LogFields() []Field
+}
+
+// ErrorDetail selects the details logged for error fields.
+type ErrorDetail uint8
+
+const (
+	// ErrorType logs the concrete type of the error as <key>.type.
+	ErrorType ErrorDetail = 1 << iota
+	// ErrorCause logs the messages of the wrapped errors as a JSON list in
+	// <key>.cause.
+	ErrorCause
+	// ErrorFields logs the attributes of errors implementing ErrorFielder
+	// as <key>.<attribute>.
+	ErrorFields
+)
+
+// NamedErr returns an error field with a key other than "error".
+func NamedErr(key string, err error) Field {
+	return Field{tp: errorType, key: key, err: err}
+}
+
+// Errs returns a field listing the messages of errs. Nil errors are skipped.
+func Errs(key string, errs []error) Field {
+	return Array(key, errorArray(errs))
+}
+
+type errorArray []error
+
+func (a errorArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, err := range a {
+		if err != nil {
+			enc.AppendString(err.Error())
+		}
+	}
+	return nil
+}
+
+// errorDetailFields returns the fields describing the error field f.
+func errorDetailFields(f Field, details ErrorDetail) []Field {
+	var fields []Field
+	if details&ErrorType != 0 {
+		fields = append(fields, errorDetail(String(f.key+".type", fmt.Sprintf("%T", f.err)), f.key))
+	}
+
+	chain := errorChain(f.err)
+	if details&ErrorCause != 0 {
+		causes := make([]string, 0, len(chain)-1)
+		last := chain[0].Error()
+		for _, err := range chain[1:] {
+			// wrappers adding only a stack repeat the message of their cause
+			if msg := err.Error(); msg != last {
+				causes = append(causes, msg)
+				last = msg
+			}
+		}
+		if len(causes) > 0 {
+			// a single value, messages may contain any separator
+			b, _ := json.Marshal(causes)
+			fields = append(fields, errorDetail(String(f.key+".cause", string(b)), f.key))
+		}
+	}
+
+	if details&ErrorFields != 0 {
+		// the outermost error wins when several errors carry the same attribute
+		seen := map[string]struct{}{}
+		for _, err := range chain {
+			fe, ok := err.(ErrorFielder)
+			if !ok {
+				continue
+			}
+			for _, attr := range fe.LogFields() {
+				if _, ok := seen[attr.key]; ok {
+					continue
+				}
+				seen[attr.key] = struct{}{}
+				attr.key = f.key + "." + attr.key
+				fields = append(fields, errorDetail(attr, f.key))
+			}
+		}
+	}
+	return fields
+}
+
+// errorDetail marks detail as a detail of the error field with the key.
+func errorDetail(detail Field, key string) Field {
+	detail.detail = len(key)
+	return detail
+}
+
+// errorChain returns err followed by the errors it wraps, depth-first.
+func errorChain(err error) []error {
+	var chain []error
+	var walk func(err error)
+	walk = func(err error) {
+		for err != nil {
+			chain = append(chain, err)
+			switch u := err.(type) {
+			case interface{ Unwrap() []error }:
+				for _, e := range u.Unwrap() {
+					walk(e)
+				}
+				return
+			case interface{ Unwrap() error }:
+				err = u.Unwrap()
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
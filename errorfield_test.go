@@ -0,0 +1,86 @@
+package pine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderError struct {
+	orderID int
+	err     error
+}
+
+func (e *orderError) Error() string { return fmt.Sprintf("order %d: %v", e.orderID, e.err) }
+
+func (e *orderError) Unwrap() error { return e.err }
+
+func (e *orderError) LogFields() []Field {
+	return []Field{Int("order_id", e.orderID), String("code", "E42")}
+}
+
+func TestLogger_ErrorDetails(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()), WithStackTraceLevel(FatalLevel),
+		WithErrorDetails(ErrorType|ErrorCause|ErrorFields))
+
+	err := fmt.Errorf("checkout: %w", &orderError{orderID: 7, err: errors.New("declined")})
+	lgr.Error("failed", Err(err))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z ERR failed "+
+		"error=\"checkout: order 7: declined\" "+
+		"error.cause=\"[\\\"order 7: declined\\\",\\\"declined\\\"]\" "+
+		"error.code=E42 error.order_id=7 error.type=\"*fmt.wrapError\"\n", buf.String())
+}
+
+func TestLogger_ErrorDetailsDisabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()), WithStackTraceLevel(FatalLevel))
+
+	lgr.Error("failed", Err(&orderError{orderID: 7, err: errors.New("declined")}))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z ERR failed error=\"order 7: declined\"\n", buf.String())
+}
+
+func TestLogger_NamedErrAndErrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()), WithStackTraceLevel(FatalLevel),
+		WithErrorDetails(ErrorType))
+
+	lgr.Error("failed",
+		NamedErr("rollback_error", errors.New("timeout")),
+		Errs("errors", []error{errors.New("a"), nil, errors.New("b")}))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z ERR failed "+
+		"errors.0=a errors.1=b rollback_error=timeout rollback_error.type=\"*errors.errorString\"\n", buf.String())
+}
+
+func TestGelfEncoder_ErrorDetails(t *testing.T) {
+	f := Err(&orderError{orderID: 7, err: errors.New("declined")})
+	fields := append([]Field{f}, errorDetailFields(f, ErrorType|ErrorCause|ErrorFields)...)
+	msg := encodeGelfFields(t, GelfFieldOptions{}, fields...)
+
+	assert.Equal(t, "order 7: declined", msg["_error"])
+	assert.Equal(t, "*pine.orderError", msg["_error_type"])
+	assert.Equal(t, `["declined"]`, msg["_error_cause"])
+	assert.Equal(t, "E42", msg["_error_code"])
+	assert.EqualValues(t, "7", fmt.Sprint(msg["_error_order_id"]))
+}
+
+func TestGelfEncoder_ErrorDetailsKeepDottedKeys(t *testing.T) {
+	f := NamedErr("req.error", errors.New("timeout"))
+	fields := append([]Field{f, String("error.type", "user")}, errorDetailFields(f, ErrorType)...)
+	msg := encodeGelfFields(t, GelfFieldOptions{}, fields...)
+
+	assert.Equal(t, "timeout", msg["_req.error"])
+	assert.Equal(t, "*errors.errorString", msg["_req.error_type"])
+	assert.Equal(t, "user", msg["_error.type"])
+}
+
+func TestErrorChain(t *testing.T) {
+	a, b := errors.New("a"), errors.New("b")
+	joined := joinedError{fmt.Errorf("wrap: %w", a), b}
+
+	chain := errorChain(joined)
+	assert.Equal(t, []error{joined, joined[0], a, b}, chain)
+}
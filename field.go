@@ -18,6 +18,8 @@ const (
 	timeType
 	errorType
 	boolType
+	objectType
+	arrayType
 )
 
 type Field struct {
@@ -28,6 +30,39 @@ type Field struct {
 	float64 float64
 	value   interface{}
 	err     error
+	// detail is the index of the '.' separating the key of an error field
+	// from the name of one of its details, 0 for other fields.
+	detail int
+}
+
+// Key returns the name of the field.
+func (f Field) Key() string {
+	return f.key
+}
+
+// Value returns the value of the field: int64 for integers, float64 for
+// floats, bool, string, time.Time, error, or the value given to Json,
+// Interface, Object and Array.
+func (f Field) Value() interface{} {
+	switch f.tp {
+	case stringType:
+		return f.string
+	case intType, int8Type, int16Type, int32Type, int64Type:
+		return f.int64
+	case float32Type, float64Type:
+		return f.float64
+	case boolType:
+		return f.int64 == 1
+	case errorType:
+		return f.err
+	case objectType, arrayType:
+		if m, ok := f.value.(marshaled); ok {
+			return m.src
+		}
+		return f.value
+	default:
+		return f.value
+	}
 }
 
 func Int(key string, val int) Field {
@@ -0,0 +1,35 @@
+//go:build !js && !plan9 && !wasip1 && !windows
+// +build !js,!plan9,!wasip1,!windows
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReopen reopens the writer on SIGHUP until the returned function is
+// called.
+func notifyReopen(w *Writer) func() {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := w.Reopen(); err != nil {
+					w.reportError(fmt.Errorf("reopen: %v", err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build js || plan9 || wasip1 || windows
+// +build js plan9 wasip1 windows
+
+package file
+
+// notifyReopen does nothing, the platform has no SIGHUP.
+func notifyReopen(w *Writer) func() {
+	return func() {}
+}
@@ -0,0 +1,357 @@
+package file
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+var errClosed = errors.New("file writer is closed")
+
+// currentTime is replaced in tests.
+var currentTime = time.Now
+
+type Options struct {
+	// MaxSize rotates the file before a write would make it larger than
+	// MaxSize bytes. Zero disables size based rotation.
+	MaxSize int64
+	// Interval rotates the file when the current interval ends, e.g. every
+	// 24h at midnight UTC. Zero disables time based rotation.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to keep. Zero keeps all.
+	MaxBackups int
+	// MaxAge removes rotated files older than MaxAge. Zero keeps all.
+	MaxAge time.Duration
+	// Compress gzips rotated files in the background.
+	Compress bool
+	// ReopenOnSIGHUP reopens the file when the process receives SIGHUP, so
+	// external tools like logrotate can move it away. It is ignored on
+	// Windows, Plan 9 and WebAssembly.
+	ReopenOnSIGHUP bool
+	// ErrorOutput receives the errors of background compression, removal
+	// and reopening. Defaults to os.Stderr.
+	ErrorOutput io.Writer
+}
+
+// Writer is an io.WriteCloser writing to a file which is rotated according
+// to its Options. The file is opened on the first write.
+type Writer struct {
+	path string
+	opts Options
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	closed       bool
+
+	millCh     chan struct{}
+	millDone   chan struct{}
+	stopSignal func()
+}
+
+func NewWriter(path string, opts Options) *Writer {
+	w := &Writer{
+		path:     path,
+		opts:     opts,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	go w.mill()
+	if opts.ReopenOnSIGHUP {
+		w.stopSignal = notifyReopen(w)
+	}
+	return w
+}
+
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, moves it to a backup and opens a new one.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errClosed
+	}
+	return w.rotate()
+}
+
+// Reopen closes and reopens the file without renaming it.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errClosed
+	}
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.closeFile()
+	w.mu.Unlock()
+
+	if w.stopSignal != nil {
+		w.stopSignal()
+	}
+	close(w.millCh)
+	<-w.millDone
+	return err
+}
+
+func (w *Writer) shouldRotate(writeLen int64) bool {
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+writeLen > w.opts.MaxSize {
+		return true
+	}
+	if w.opts.Interval > 0 && !currentTime().Before(w.nextRotation) {
+		return true
+	}
+	return false
+}
+
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	if w.opts.Interval > 0 {
+		w.nextRotation = currentTime().Truncate(w.opts.Interval).Add(w.opts.Interval)
+	}
+	return nil
+}
+
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *Writer) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(w.path); err == nil {
+		if err := os.Rename(w.path, w.backupName(currentTime())); err != nil {
+			return err
+		}
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (w *Writer) backupName(t time.Time) string {
+	dir, prefix, ext := w.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+		if !exists(name) && !exists(name+compressSuffix) {
+			return name
+		}
+		// several rotations within a millisecond
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (w *Writer) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(w.path)
+	base := filepath.Base(w.path)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return dir, prefix, ext
+}
+
+// mill compresses and removes backups after every rotation.
+func (w *Writer) mill() {
+	defer close(w.millDone)
+	for range w.millCh {
+		if err := w.millRun(); err != nil {
+			w.reportError(err)
+		}
+	}
+}
+
+// reportError writes an error of a background task to the error output.
+func (w *Writer) reportError(err error) {
+	out := w.opts.ErrorOutput
+	if out == nil {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "pine/file: %v\n", err)
+}
+
+type backup struct {
+	path      string
+	timestamp time.Time
+}
+
+func (w *Writer) backups() ([]backup, error) {
+	dir, prefix, ext := w.nameParts()
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimPrefix(name, prefix)
+		ts = strings.TrimSuffix(ts, compressSuffix)
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(ts, ext))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), timestamp: t})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+	return backups, nil
+}
+
+func (w *Writer) millRun() error {
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var keep []backup
+	var remove []backup
+	cutoff := currentTime().Add(-w.opts.MaxAge)
+	for i, b := range backups {
+		switch {
+		case w.opts.MaxBackups > 0 && i >= w.opts.MaxBackups:
+			remove = append(remove, b)
+		case w.opts.MaxAge > 0 && b.timestamp.Before(cutoff):
+			remove = append(remove, b)
+		default:
+			keep = append(keep, b)
+		}
+	}
+
+	for _, b := range remove {
+		if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if w.opts.Compress {
+		for _, b := range keep {
+			if strings.HasSuffix(b.path, compressSuffix) {
+				continue
+			}
+			if err := compressFile(b.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
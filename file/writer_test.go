@@ -0,0 +1,187 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+func useFakeClock(t *testing.T) *fakeClock {
+	t.Helper()
+	c := &fakeClock{now: time.Date(2022, time.August, 10, 21, 29, 59, 123000000, time.UTC)}
+	currentTime = c.Now
+	t.Cleanup(func() {
+		currentTime = time.Now
+	})
+	return c
+}
+
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestWriter_MaxSize(t *testing.T) {
+	clock := useFakeClock(t)
+	dir := t.TempDir()
+	w := NewWriter(filepath.Join(dir, "app.log"), Options{MaxSize: 11})
+
+	_, err := w.Write([]byte("12345\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("6789\n"))
+	require.NoError(t, err)
+	clock.Add(time.Second)
+	_, err = w.Write([]byte("abc\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"app-2022-08-10T21-30-00.123.log", "app.log"}, files(t, dir))
+	assert.Equal(t, "12345\n6789\n", read(t, filepath.Join(dir, "app-2022-08-10T21-30-00.123.log")))
+	assert.Equal(t, "abc\n", read(t, filepath.Join(dir, "app.log")))
+}
+
+func TestWriter_Interval(t *testing.T) {
+	clock := useFakeClock(t)
+	dir := t.TempDir()
+	w := NewWriter(filepath.Join(dir, "app.log"), Options{Interval: time.Hour})
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	clock.Add(time.Minute)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	clock.Set(time.Date(2022, time.August, 10, 22, 0, 0, 0, time.UTC))
+	_, err = w.Write([]byte("third\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"app-2022-08-10T22-00-00.000.log", "app.log"}, files(t, dir))
+	assert.Equal(t, "first\nsecond\n", read(t, filepath.Join(dir, "app-2022-08-10T22-00-00.000.log")))
+	assert.Equal(t, "third\n", read(t, filepath.Join(dir, "app.log")))
+}
+
+func TestWriter_MaxBackupsAndCompress(t *testing.T) {
+	clock := useFakeClock(t)
+	dir := t.TempDir()
+	w := NewWriter(filepath.Join(dir, "app.log"), Options{MaxBackups: 2, Compress: true})
+
+	for _, msg := range []string{"1", "2", "3", "4"} {
+		_, err := w.Write([]byte(msg))
+		require.NoError(t, err)
+		clock.Add(time.Second)
+		require.NoError(t, w.Rotate())
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"app-2022-08-10T21-30-02.123.log.gz", "app-2022-08-10T21-30-03.123.log.gz", "app.log"}, files(t, dir))
+
+	f, err := os.Open(filepath.Join(dir, "app-2022-08-10T21-30-03.123.log.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "4", string(b))
+}
+
+func TestWriter_MaxAge(t *testing.T) {
+	clock := useFakeClock(t)
+	dir := t.TempDir()
+	w := NewWriter(filepath.Join(dir, "app.log"), Options{MaxAge: time.Hour})
+
+	_, err := w.Write([]byte("old"))
+	require.NoError(t, err)
+	require.NoError(t, w.Rotate())
+	clock.Add(2 * time.Hour)
+	_, err = w.Write([]byte("new"))
+	require.NoError(t, err)
+	require.NoError(t, w.Rotate())
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"app-2022-08-10T23-29-59.123.log", "app.log"}, files(t, dir))
+}
+
+func TestWriter_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w := NewWriter(path, Options{})
+
+	_, err := w.Write([]byte("before\n"))
+	require.NoError(t, err)
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, w.Reopen())
+	_, err = w.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "before\n", read(t, path+".1"))
+	assert.Equal(t, "after\n", read(t, path))
+
+	_, err = w.Write([]byte("closed\n"))
+	assert.Error(t, err)
+}
+
+func TestWriter_ErrorOutput(t *testing.T) {
+	clock := useFakeClock(t)
+	dir := t.TempDir()
+	errOut := &bytes.Buffer{}
+	w := NewWriter(filepath.Join(dir, "app.log"), Options{Compress: true, ErrorOutput: errOut})
+
+	_, err := w.Write([]byte("1"))
+	require.NoError(t, err)
+	// a directory in place of the compressed backup makes compression fail
+	backup := filepath.Join(dir, "app-2022-08-10T21-00-00.000.log")
+	require.NoError(t, ioutil.WriteFile(backup, []byte("0"), 0644))
+	require.NoError(t, os.Mkdir(backup+".gz", 0755))
+	clock.Add(time.Second)
+	require.NoError(t, w.Rotate())
+	require.NoError(t, w.Close())
+
+	assert.Contains(t, errOut.String(), "pine/file: ")
+	assert.Contains(t, errOut.String(), "app-2022-08-10T21-00-00.000.log.gz")
+}
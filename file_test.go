@@ -0,0 +1,27 @@
+package pine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	console := &bytes.Buffer{}
+	lgr := New(WithColors(), Output(console), WithClock(newTestClock()), File(path, RotateOptions{MaxSize: 1024}),
+		FileLevel(WarnLevel), FileFormat(JSONFormat))
+
+	lgr.Info("hello")
+	lgr.Warn("careful", Int("i", 1))
+	lgr.Close()
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"time":"2022-08-10T21:29:59.123Z","level":"warn","message":"careful","i":1}`+"\n", string(b))
+	assert.Contains(t, console.String(), "hello")
+}
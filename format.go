@@ -0,0 +1,91 @@
+package pine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format selects how entries are rendered to the console output.
+type Format int8
+
+const (
+	// ConsoleFormat renders human readable key=value lines.
+	ConsoleFormat Format = iota
+	// JSONFormat renders one JSON object per line.
+	JSONFormat
+)
+
+func (f Format) String() string {
+	switch f {
+	case ConsoleFormat:
+		return "console"
+	case JSONFormat:
+		return "json"
+	default:
+		return ""
+	}
+}
+
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+func (f *Format) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "console", "text", "":
+		*f = ConsoleFormat
+	case "json":
+		*f = JSONFormat
+	default:
+		return fmt.Errorf("invalid format: %q", text)
+	}
+	return nil
+}
+
+func ParseFormat(text string) (Format, error) {
+	var format Format
+	err := format.UnmarshalText([]byte(text))
+	return format, err
+}
+
+// JSONKeys holds the names of the entry keys produced by the JSON format.
+// Empty names fall back to the defaults.
+type JSONKeys struct {
+	Time    string
+	Level   string
+	Message string
+	Caller  string
+	Stack   string
+	Logger  string
+}
+
+var defaultJSONKeys = JSONKeys{
+	Time:    "time",
+	Level:   "level",
+	Message: "message",
+	Caller:  "caller",
+	Stack:   "stack",
+	Logger:  "logger",
+}
+
+func (k JSONKeys) withDefaults() JSONKeys {
+	if k.Time == "" {
+		k.Time = defaultJSONKeys.Time
+	}
+	if k.Level == "" {
+		k.Level = defaultJSONKeys.Level
+	}
+	if k.Message == "" {
+		k.Message = defaultJSONKeys.Message
+	}
+	if k.Caller == "" {
+		k.Caller = defaultJSONKeys.Caller
+	}
+	if k.Stack == "" {
+		k.Stack = defaultJSONKeys.Stack
+	}
+	if k.Logger == "" {
+		k.Logger = defaultJSONKeys.Logger
+	}
+	return k
+}
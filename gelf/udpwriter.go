@@ -0,0 +1,173 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+type CompressionType int
+
+const (
+	CompressGzip CompressionType = iota
+	CompressZlib
+	CompressNone
+)
+
+const (
+	// ChunkSizeWAN fits into the MTU of most internet paths.
+	ChunkSizeWAN = 1420
+	// ChunkSizeLAN is suitable for local networks with jumbo frames.
+	ChunkSizeLAN = 8154
+
+	DefaultChunkSize = ChunkSizeWAN
+
+	// MaxChunks is the maximum number of chunks a message can be split into.
+	MaxChunks = 128
+
+	chunkHeaderSize = 12
+)
+
+var chunkMagic = []byte{0x1e, 0x0f}
+
+type UDPWriter struct {
+	addr             string
+	conn             net.Conn
+	mu               sync.Mutex
+	ChunkSize        int
+	CompressionType  CompressionType
+	CompressionLevel int
+}
+
+func NewUDPWriter(addr string) *UDPWriter {
+	w := new(UDPWriter)
+	w.ChunkSize = DefaultChunkSize
+	w.CompressionType = CompressGzip
+	w.CompressionLevel = flate.BestSpeed
+	w.addr = addr
+	return w
+}
+
+func (w *UDPWriter) connect() error {
+	if w.conn != nil {
+		return nil
+	}
+	conn, err := net.Dial("udp", w.addr)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *UDPWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// Write sends a single GELF message. The null byte and new line framing
+// required by the TCP transport are stripped.
+func (w *UDPWriter) Write(p []byte) (n int, err error) {
+	msg := bytes.TrimRight(p, "\n\x00")
+
+	zBytes, err := w.compress(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+
+	chunkSize := w.ChunkSize
+	if chunkSize <= chunkHeaderSize {
+		chunkSize = DefaultChunkSize
+	}
+
+	if len(zBytes) <= chunkSize {
+		if _, err := w.conn.Write(zBytes); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if err := w.writeChunked(zBytes, chunkSize); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *UDPWriter) writeChunked(zBytes []byte, chunkSize int) error {
+	payloadSize := chunkSize - chunkHeaderSize
+	count := (len(zBytes) + payloadSize - 1) / payloadSize
+	if count > MaxChunks {
+		return fmt.Errorf("message too large: %d bytes require %d chunks, maximum is %d", len(zBytes), count, MaxChunks)
+	}
+
+	msgID := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, msgID); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 0, chunkSize)
+	for i := 0; i < count; i++ {
+		start := i * payloadSize
+		end := start + payloadSize
+		if end > len(zBytes) {
+			end = len(zBytes)
+		}
+
+		chunk = chunk[:0]
+		chunk = append(chunk, chunkMagic...)
+		chunk = append(chunk, msgID...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, zBytes[start:end]...)
+
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *UDPWriter) compress(p []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	var err error
+
+	switch w.CompressionType {
+	case CompressNone:
+		return p, nil
+	case CompressGzip:
+		zw, err = gzip.NewWriterLevel(&buf, w.CompressionLevel)
+	case CompressZlib:
+		zw, err = zlib.NewWriterLevel(&buf, w.CompressionLevel)
+	default:
+		return nil, fmt.Errorf("unknown compression type %d", w.CompressionType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = zw.Write(p); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
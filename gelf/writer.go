@@ -1,6 +1,7 @@
 package gelf
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
@@ -20,6 +21,7 @@ type TCPWriter struct {
 	mu             sync.Mutex
 	MaxReconnect   int
 	ReconnectDelay time.Duration
+	TLSConfig      *tls.Config
 }
 
 func NewTCPWriter(addr string) *TCPWriter {
@@ -31,13 +33,34 @@ func NewTCPWriter(addr string) *TCPWriter {
 	return w
 }
 
+// NewTLSWriter creates a TCPWriter which wraps the connection in TLS. The
+// config may carry a custom CA pool, client certificates and a server name.
+func NewTLSWriter(addr string, config *tls.Config) *TCPWriter {
+	w := NewTCPWriter(addr)
+	w.proto = "tls"
+	w.TLSConfig = config
+	return w
+}
+
+func (w *TCPWriter) dial() (net.Conn, error) {
+	if w.proto == "tls" {
+		conn, err := tls.Dial("tcp", w.addr, w.TLSConfig)
+		if err != nil {
+			// avoid returning a nil *tls.Conn wrapped in a non-nil net.Conn
+			return nil, err
+		}
+		return conn, nil
+	}
+	return net.Dial("tcp", w.addr)
+}
+
 func (w *TCPWriter) connect() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	if w.conn != nil {
 		return nil
 	}
-	conn, err := net.Dial("tcp", w.addr)
+	conn, err := w.dial()
 	if err != nil {
 		return err
 	}
@@ -89,7 +112,7 @@ func (w *TCPWriter) writeToSocketWithReconnectAttempts(zBytes []byte) (n int, er
 		}
 		if err != nil {
 			time.Sleep(w.ReconnectDelay * time.Second)
-			w.conn, errConn = net.Dial("tcp", w.addr)
+			w.conn, errConn = w.dial()
 		} else {
 			break
 		}
@@ -1,20 +1,32 @@
 package pine
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net"
+	"os"
+	"strings"
 	"sync"
+	"testing"
 	"time"
 
+	"github.com/go-pckg/pine/gelf"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type TCPServer struct {
-	addr string
-	mu   sync.Mutex
+	addr      string
+	tlsConfig *tls.Config
+	mu        sync.Mutex
 
 	closed     bool
 	listener   net.Listener
@@ -27,12 +39,22 @@ func NewTCPServer(addr string) *TCPServer {
 	return &TCPServer{addr: addr, activeConn: map[net.Conn]struct{}{}}
 }
 
+func NewTLSServer(addr string, cfg *tls.Config) *TCPServer {
+	server := NewTCPServer(addr)
+	server.tlsConfig = cfg
+	return server
+}
+
 func (t *TCPServer) Addr() string {
 	return t.listener.Addr().String()
 }
 
 func (t *TCPServer) Run() (err error) {
-	t.listener, err = net.Listen("tcp", t.addr)
+	if t.tlsConfig != nil {
+		t.listener, err = tls.Listen("tcp", t.addr, t.tlsConfig)
+	} else {
+		t.listener, err = net.Listen("tcp", t.addr)
+	}
 	if err != nil {
 		return err
 	}
@@ -142,3 +164,150 @@ func detectMessage(data []byte) (hasMessage bool, first int, last int) {
 	}
 	return
 }
+
+type UDPServer struct {
+	mu   sync.Mutex
+	conn net.PacketConn
+
+	chunks   map[string][][]byte
+	messages []string
+	done     chan struct{}
+}
+
+func NewUDPServer() (*UDPServer, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &UDPServer{conn: conn, chunks: map[string][][]byte{}, done: make(chan struct{})}
+	go s.serve()
+	return s, nil
+}
+
+func (s *UDPServer) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *UDPServer) Close() error {
+	err := s.conn.Close()
+	<-s.done
+	return err
+}
+
+func (s *UDPServer) Messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.messages...)
+}
+
+func (s *UDPServer) serve() {
+	defer close(s.done)
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		packet := append([]byte{}, buf[:n]...)
+		if msg, ok := s.assemble(packet); ok {
+			s.mu.Lock()
+			s.messages = append(s.messages, string(decompress(msg)))
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *UDPServer) assemble(packet []byte) ([]byte, bool) {
+	if len(packet) < 12 || packet[0] != 0x1e || packet[1] != 0x0f {
+		return packet, true
+	}
+	id := string(packet[2:10])
+	seq, count := int(packet[10]), int(packet[11])
+	if s.chunks[id] == nil {
+		s.chunks[id] = make([][]byte, count)
+	}
+	s.chunks[id][seq] = packet[12:]
+	for i := range s.chunks[id] {
+		if s.chunks[id][i] == nil {
+			return nil, false
+		}
+	}
+	msg := bytes.Join(s.chunks[id], nil)
+	delete(s.chunks, id)
+	return msg, true
+}
+
+func decompress(msg []byte) []byte {
+	var r io.ReadCloser
+	var err error
+	switch {
+	case len(msg) > 2 && msg[0] == 0x1f && msg[1] == 0x8b:
+		r, err = gzip.NewReader(bytes.NewReader(msg))
+	case len(msg) > 2 && msg[0] == 0x78:
+		r, err = zlib.NewReader(bytes.NewReader(msg))
+	default:
+		return msg
+	}
+	if err != nil {
+		return msg
+	}
+	defer r.Close()
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return msg
+	}
+	return out
+}
+
+func TestLogger_GraylogUDP(t *testing.T) {
+	getCaller = func(skip int) (pc uintptr, file string, line int, ok bool) {
+		return 0, "logger_test.go", 2, true
+	}
+	defaultHostname = func() string {
+		return "test.local"
+	}
+	defer func() {
+		defaultHostname = func() string {
+			hostname, _ := os.Hostname()
+			return hostname
+		}
+	}()
+
+	tests := []struct {
+		name        string
+		compression gelf.CompressionType
+	}{
+		{name: "gzip", compression: gelf.CompressGzip},
+		{name: "zlib", compression: gelf.CompressZlib},
+		{name: "none", compression: gelf.CompressNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			server, err := NewUDPServer()
+			require.NoError(t, err)
+
+			lgr := New(Output(&bytes.Buffer{}), WithClock(newTestClock()), Graylog("udp://"+server.Addr()),
+				GraylogCompression(tt.compression), GraylogChunkSize(100))
+			lgr.Info("hello", String("A", "B"))
+			lgr.Info("hello2", String("long", strings.Repeat("x", 2000)))
+			lgr.Close()
+
+			require.Eventually(t, func() bool { return len(server.Messages()) == 2 }, time.Second, time.Millisecond*10)
+			require.NoError(t, server.Close())
+
+			messages := server.Messages()
+			assert.Equal(t, `{"version":"1.1","host":"test.local","short_message":"hello","timestamp":1660166999.123,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`, messages[0])
+			assert.Equal(t, `{"version":"1.1","host":"test.local","short_message":"hello2","timestamp":1660166999.123,"level":6,"_caller":"logger_test.go:2","_file":"logger_test.go","_line":2,"_long":"`+strings.Repeat("x", 2000)+`"}`, messages[1])
+		})
+	}
+}
+
+func TestUDPWriter_TooManyChunks(t *testing.T) {
+	w := gelf.NewUDPWriter("127.0.0.1:1")
+	w.CompressionType = gelf.CompressNone
+	w.ChunkSize = 20
+	_, err := w.Write(bytes.Repeat([]byte("x"), 8*gelf.MaxChunks+1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GelfTimeFormat selects how time fields are sent to Graylog.
+type GelfTimeFormat int8
+
+const (
+	// GelfTimeRFC3339 sends times as RFC 3339 strings with nanoseconds.
+	GelfTimeRFC3339 GelfTimeFormat = iota
+	// GelfTimeUnix sends times as seconds since the epoch with microseconds.
+	GelfTimeUnix
+	// GelfTimeUnixMilli sends times as milliseconds since the epoch.
+	GelfTimeUnixMilli
+)
+
+// GelfMapping selects how values GELF has no type for are sent: bools,
+// objects, arrays and Json fields.
+type GelfMapping int8
+
+const (
+	// GelfFlatten keeps bools and flattens objects, arrays and Json fields
+	// into one additional field per value, e.g. _req_user_id.
+	GelfFlatten GelfMapping = iota
+	// GelfStringify sends bools as "true" or "false" and objects, arrays and
+	// Json fields as JSON strings.
+	GelfStringify
+)
+
+// GelfFieldOptions controls how fields are mapped to GELF additional fields.
+// Numbers are always sent as numbers.
+type GelfFieldOptions struct {
+	TimeFormat GelfTimeFormat
+	Mapping    GelfMapping
+}
+
+// gelfKey returns the additional field name for key. Characters outside
+// [\w.-] are replaced with '_' and the reserved _id is renamed to _id_.
+func gelfKey(key string) string {
+	var b strings.Builder
+	b.Grow(len(key) + 1)
+	b.WriteByte('_')
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.String() == "_id" {
+		return "_id_"
+	}
+	return b.String()
+}
+
+// gelfFieldKey returns the key of field before sanitizing. Error details are
+// joined to their error key with '_', e.g. error.type is sent as _error_type.
+func gelfFieldKey(field Field) string {
+	if field.detail == 0 {
+		return field.key
+	}
+	return field.key[:field.detail] + "_" + field.key[field.detail+1:]
+}
+
+func (l gelfEncoder) appendValue(m map[string]interface{}, key string, v interface{}) {
+	switch t := v.(type) {
+	case objectValue, arrayValue:
+		if l.opts.Mapping == GelfStringify {
+			b := &bytes.Buffer{}
+			appendJSONValue(b, t)
+			m[gelfKey(key)] = b.String()
+			return
+		}
+		flattenValue(key, "_", t, func(key string, leaf interface{}) {
+			l.appendValue(m, key, leaf)
+		})
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			m[gelfKey(key)] = strconv.FormatFloat(t, 'g', -1, 64)
+			return
+		}
+		m[gelfKey(key)] = t
+	case bool:
+		if l.opts.Mapping == GelfStringify {
+			m[gelfKey(key)] = strconv.FormatBool(t)
+			return
+		}
+		m[gelfKey(key)] = t
+	case time.Time:
+		switch l.opts.TimeFormat {
+		case GelfTimeUnix:
+			m[gelfKey(key)] = float64(t.UnixNano()/int64(time.Microsecond)) / 1e6
+		case GelfTimeUnixMilli:
+			m[gelfKey(key)] = t.UnixNano() / int64(time.Millisecond)
+		default:
+			m[gelfKey(key)] = t.Format(time.RFC3339Nano)
+		}
+	case nil:
+		// additional fields must not be null, like null Json values
+		m[gelfKey(key)] = ""
+	default:
+		m[gelfKey(key)] = v
+	}
+}
+
+// jsonTreeValue converts a value to the representation of marshaled
+// objects, so Json fields can be flattened like Object fields.
+func jsonTreeValue(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return convertJSONTree(tree), nil
+}
+
+func convertJSONTree(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		obj := make(objectValue, 0, len(t))
+		for _, k := range keys {
+			obj = append(obj, objectEntry{key: k, value: convertJSONTree(t[k])})
+		}
+		return obj
+	case []interface{}:
+		arr := make(arrayValue, 0, len(t))
+		for _, e := range t {
+			arr = append(arr, convertJSONTree(e))
+		}
+		return arr
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case nil:
+		return ""
+	default:
+		return t
+	}
+}
@@ -0,0 +1,81 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeGelfFields(t *testing.T, opts GelfFieldOptions, fields ...Field) map[string]interface{} {
+	t.Helper()
+	ent := &Entry{level: InfoLevel, time: testDate, message: "hello"}
+	b, err := newGelfEncoder(nil, opts, defaultGelfMessageOptions).encodeEntry(ent, fields)
+	require.NoError(t, err)
+
+	var msg map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(bytes.TrimRight(b, "\n\x00")))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&msg))
+	return msg
+}
+
+func TestGelfEncoder_TypedFields(t *testing.T) {
+	msg := encodeGelfFields(t, GelfFieldOptions{},
+		Int("latency_ms", 42),
+		Float64("ratio", 0.5),
+		Float64("nan", math.NaN()),
+		Bool("ok", true),
+		String("s", "42"),
+		Time("at", testDate),
+		Json("json", map[string]interface{}{"a": map[string]interface{}{"b": 1}, "c": []string{"x"}}),
+	)
+
+	assert.Equal(t, json.Number("42"), msg["_latency_ms"])
+	assert.Equal(t, json.Number("0.5"), msg["_ratio"])
+	assert.Equal(t, "NaN", msg["_nan"])
+	assert.Equal(t, true, msg["_ok"])
+	assert.Equal(t, "42", msg["_s"])
+	assert.Equal(t, "2022-08-10T21:29:59.123456789Z", msg["_at"])
+	assert.Equal(t, json.Number("1"), msg["_json_a_b"])
+	assert.Equal(t, "x", msg["_json_c_0"])
+}
+
+func TestGelfEncoder_Stringify(t *testing.T) {
+	msg := encodeGelfFields(t, GelfFieldOptions{Mapping: GelfStringify, TimeFormat: GelfTimeUnixMilli},
+		Bool("ok", true),
+		Int("i", 1),
+		Time("at", testDate),
+		Strings("tags", []string{"a", "b"}),
+		Json("json", map[string]int{"a": 1}),
+	)
+
+	assert.Equal(t, "true", msg["_ok"])
+	assert.Equal(t, json.Number("1"), msg["_i"])
+	assert.Equal(t, json.Number("1660166999123"), msg["_at"])
+	assert.Equal(t, `["a","b"]`, msg["_tags"])
+	assert.Equal(t, `{"a":1}`, msg["_json"])
+}
+
+func TestGelfEncoder_NilValues(t *testing.T) {
+	var req *testRequest
+	msg := encodeGelfFields(t, GelfFieldOptions{}, Object("obj", req), Json("json", map[string]interface{}{"a": nil}))
+	assert.Equal(t, "", msg["_obj"])
+	assert.Equal(t, "", msg["_json_a"])
+}
+
+func TestGelfEncoder_TimeUnix(t *testing.T) {
+	msg := encodeGelfFields(t, GelfFieldOptions{TimeFormat: GelfTimeUnix}, Time("at", testDate.Truncate(time.Millisecond)))
+	assert.Equal(t, json.Number("1660166999.123"), msg["_at"])
+}
+
+func TestGelfKey(t *testing.T) {
+	assert.Equal(t, "_id_", gelfKey("id"))
+	assert.Equal(t, "_user_id", gelfKey("user_id"))
+	assert.Equal(t, "_req.path-x", gelfKey("req.path-x"))
+	assert.Equal(t, "_a_b_c_", gelfKey("a b/cé"))
+}
@@ -0,0 +1,85 @@
+package pine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// GelfFullMessage selects what is sent as full_message.
+type GelfFullMessage uint8
+
+const (
+	// FullMessageText sends the complete message when short_message is cut
+	// to its first line or truncated.
+	FullMessageText GelfFullMessage = 1 << iota
+	// FullMessageStack sends the stack trace instead of the _stack field.
+	FullMessageStack
+	// FullMessageErrors sends error fields with their details (%+v), e.g.
+	// the stack traces of github.com/pkg/errors.
+	FullMessageErrors
+)
+
+// GelfMessageOptions controls the timestamp, short_message and full_message
+// of GELF messages.
+type GelfMessageOptions struct {
+	// TimestampPrecision is time.Millisecond (default) or time.Microsecond.
+	TimestampPrecision time.Duration
+	// ShortMessageLength truncates short_message to as many characters. Zero
+	// disables truncation.
+	ShortMessageLength int
+	// FullMessage defaults to FullMessageText. Use GraylogNoFullMessage to
+	// send none.
+	FullMessage GelfFullMessage
+}
+
+var defaultGelfMessageOptions = GelfMessageOptions{
+	TimestampPrecision: time.Millisecond,
+	FullMessage:        FullMessageText,
+}
+
+func (o GelfMessageOptions) timestamp(t time.Time) float64 {
+	if o.TimestampPrecision == time.Microsecond {
+		return float64(t.UnixNano()/int64(time.Microsecond)) / 1e6
+	}
+	return float64(t.UnixNano()/int64(time.Millisecond)) / 1e3
+}
+
+// shortMessage returns the first non-blank line of msg truncated to the
+// configured length, and whether it differs from msg. GELF requires a
+// short_message, so a blank msg is sent as "-".
+func (o GelfMessageOptions) shortMessage(msg string) (string, bool) {
+	short := "-"
+	for _, line := range strings.FieldsFunc(msg, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		if strings.TrimSpace(line) != "" {
+			short = line
+			break
+		}
+	}
+	if o.ShortMessageLength > 0 && utf8.RuneCountInString(short) > o.ShortMessageLength {
+		short = string([]rune(short)[:o.ShortMessageLength])
+	}
+	return short, short != msg
+}
+
+// fullMessage returns the full_message for the entry, or an empty string.
+func (o GelfMessageOptions) fullMessage(ent *Entry, fields []Field, cut bool) string {
+	var parts []string
+	if cut && o.FullMessage&FullMessageText != 0 {
+		parts = append(parts, ent.message)
+	}
+	if o.FullMessage&FullMessageErrors != 0 {
+		for i := range fields {
+			if fields[i].tp == errorType && fields[i].err != nil {
+				parts = append(parts, fmt.Sprintf("%s: %+v", fields[i].key, fields[i].err))
+			}
+		}
+	}
+	if o.FullMessage&FullMessageStack != 0 {
+		for i, st := range ent.stacks {
+			parts = append(parts, stackKey("stack", i)+":"+st.String())
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
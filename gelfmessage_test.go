@@ -0,0 +1,108 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeGelfMessage(t *testing.T, opts GelfMessageOptions, ent *Entry, fields ...Field) map[string]interface{} {
+	t.Helper()
+	b, err := newGelfEncoder(nil, GelfFieldOptions{}, opts).encodeEntry(ent, fields)
+	require.NoError(t, err)
+
+	var msg map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(bytes.TrimRight(b, "\n\x00")))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&msg))
+	return msg
+}
+
+func TestGelfEncoder_Timestamp(t *testing.T) {
+	ent := &Entry{level: InfoLevel, time: testDate, message: "hello"}
+
+	msg := encodeGelfMessage(t, defaultGelfMessageOptions, ent)
+	assert.Equal(t, json.Number("1660166999.123"), msg["timestamp"])
+
+	msg = encodeGelfMessage(t, GelfMessageOptions{TimestampPrecision: time.Microsecond}, ent)
+	assert.Equal(t, json.Number("1660166999.123456"), msg["timestamp"])
+}
+
+func TestGelfEncoder_ShortMessage(t *testing.T) {
+	ent := &Entry{level: InfoLevel, time: testDate, message: "first line\nsecond line"}
+
+	msg := encodeGelfMessage(t, defaultGelfMessageOptions, ent)
+	assert.Equal(t, "first line", msg["short_message"])
+	assert.Equal(t, "first line\nsecond line", msg["full_message"])
+
+	msg = encodeGelfMessage(t, GelfMessageOptions{ShortMessageLength: 5}, ent)
+	assert.Equal(t, "first", msg["short_message"])
+	assert.Nil(t, msg["full_message"])
+
+	ent.message = "hello"
+	msg = encodeGelfMessage(t, GelfMessageOptions{ShortMessageLength: 5, FullMessage: FullMessageText}, ent)
+	assert.Equal(t, "hello", msg["short_message"])
+	assert.Nil(t, msg["full_message"])
+}
+
+func TestGraylogMessageOptions_Defaults(t *testing.T) {
+	cfg := config{}
+	GraylogMessageOptions(GelfMessageOptions{ShortMessageLength: 5}).apply(&cfg)
+	assert.Equal(t, GelfMessageOptions{
+		TimestampPrecision: time.Millisecond,
+		ShortMessageLength: 5,
+		FullMessage:        FullMessageText,
+	}, cfg.gelfConfig.MessageOptions)
+
+	ent := &Entry{level: InfoLevel, time: testDate, message: "first line"}
+	msg := encodeGelfMessage(t, cfg.gelfConfig.MessageOptions, ent)
+	assert.Equal(t, "first", msg["short_message"])
+	assert.Equal(t, "first line", msg["full_message"])
+
+}
+
+func TestGraylogNoFullMessage(t *testing.T) {
+	os.Clearenv()
+	lgr, shutdown := newLoggerWithGraylog(t, WithClock(newTestClock()), GraylogNoFullMessage(),
+		GraylogMessageOptions(GelfMessageOptions{ShortMessageLength: 5, FullMessage: FullMessageText | FullMessageStack}))
+	lgr.Info("first line")
+	_, messages := shutdown(t)
+
+	require.Len(t, messages, 1)
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(messages[0]), &msg))
+	assert.Equal(t, "first", msg["short_message"])
+	assert.Nil(t, msg["full_message"])
+}
+
+func TestGelfEncoder_BlankShortMessage(t *testing.T) {
+	ent := &Entry{level: InfoLevel, time: testDate, message: "\n  \nsecond line\nthird line"}
+	msg := encodeGelfMessage(t, defaultGelfMessageOptions, ent)
+	assert.Equal(t, "second line", msg["short_message"])
+	assert.Equal(t, "\n  \nsecond line\nthird line", msg["full_message"])
+
+	ent.message = ""
+	msg = encodeGelfMessage(t, defaultGelfMessageOptions, ent)
+	assert.Equal(t, "-", msg["short_message"])
+	assert.Nil(t, msg["full_message"])
+}
+
+func TestGelfEncoder_FullMessage(t *testing.T) {
+	err := errors.Wrap(errors.New("root cause"), "query failed")
+	ent := &Entry{level: ErrorLevel, time: testDate, message: "failed", stacks: []Stack{PkgErrorsStack(err)}}
+
+	msg := encodeGelfMessage(t, GelfMessageOptions{FullMessage: FullMessageStack | FullMessageErrors}, ent, Err(err))
+	full := msg["full_message"].(string)
+	assert.True(t, strings.HasPrefix(full, "error: root cause\n"), full)
+	assert.Contains(t, full, "query failed")
+	assert.Contains(t, full, "\n\nstack:\ngithub.com/go-pckg/pine.TestGelfEncoder_FullMessage")
+	assert.Nil(t, msg["_stack"])
+	assert.Equal(t, "query failed: root cause", msg["_error"])
+}
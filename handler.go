@@ -0,0 +1,172 @@
+package pine
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Handler is a destination for log entries. Handlers of a logger and of the
+// loggers derived from it with With are called one at a time, so Write does
+// not need to be safe for concurrent use.
+type Handler interface {
+	// Enabled reports whether the handler accepts entries of the level.
+	Enabled(lvl Level) bool
+	// Write handles a single entry. The record must not be retained after
+	// Write returns.
+	Write(rec Record) error
+	// Close flushes and releases the resources of the handler.
+	Close() error
+}
+
+// Syncer is implemented by handlers which deliver entries asynchronously.
+type Syncer interface {
+	// Sync waits until the pending entries are delivered or ctx is done.
+	Sync(ctx context.Context) error
+}
+
+type statsProvider interface {
+	stats() Stats
+}
+
+// contextCloser is implemented by handlers whose Close waits for pending
+// entries, so the wait can be bounded by ctx.
+type contextCloser interface {
+	closeContext(ctx context.Context) error
+}
+
+// HandlerWithLevel returns a handler which passes entries up to lvl to h.
+func HandlerWithLevel(h Handler, lvl *LevelValue) Handler {
+	return &leveledHandler{Handler: h, level: lvl}
+}
+
+type leveledHandler struct {
+	Handler
+	level *LevelValue
+}
+
+func (h *leveledHandler) levelValue() *LevelValue {
+	return h.level
+}
+
+func (h *leveledHandler) Enabled(lvl Level) bool {
+	return h.level.GetLevel() >= lvl && h.Handler.Enabled(lvl)
+}
+
+type consoleHandler struct {
+	level   *LevelValue
+	encoder encoder
+	out     io.Writer
+}
+
+func (h *consoleHandler) levelValue() *LevelValue {
+	return h.level
+}
+
+func (h *consoleHandler) Enabled(lvl Level) bool {
+	return h.level.GetLevel() >= lvl
+}
+
+func (h *consoleHandler) Write(rec Record) error {
+	ent := rec.e
+	buf, err := h.encoder.encodeEntry(ent, ent.fields)
+	if err != nil {
+		return err
+	}
+	_, err = h.out.Write(buf)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *consoleHandler) Close() error {
+	return nil
+}
+
+type gelfHandler struct {
+	level   *LevelValue
+	encoder encoder
+	out     io.WriteCloser
+	mu      sync.Mutex
+}
+
+func (h *gelfHandler) levelValue() *LevelValue {
+	return h.level
+}
+
+func (h *gelfHandler) Enabled(lvl Level) bool {
+	return h.level.GetLevel() >= lvl
+}
+
+func (h *gelfHandler) Write(rec Record) error {
+	ent := rec.e
+	buf, err := h.encoder.encodeEntry(ent, ent.fields)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.out.Write(buf)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *gelfHandler) Sync(ctx context.Context) error {
+	if w, ok := h.out.(*asyncWriter); ok {
+		return w.Sync(ctx)
+	}
+	return nil
+}
+
+func (h *gelfHandler) stats() Stats {
+	if w, ok := h.out.(*asyncWriter); ok {
+		return Stats{Dropped: w.Dropped(), Failed: w.Failed()}
+	}
+	return Stats{}
+}
+
+func (h *gelfHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.out.Close()
+}
+
+func (h *gelfHandler) closeContext(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if w, ok := h.out.(*asyncWriter); ok {
+		return w.closeContext(ctx)
+	}
+	return h.out.Close()
+}
+
+type fileHandler struct {
+	level   *LevelValue
+	encoder encoder
+	out     io.WriteCloser
+}
+
+func (h *fileHandler) levelValue() *LevelValue {
+	return h.level
+}
+
+func (h *fileHandler) Enabled(lvl Level) bool {
+	return h.level.GetLevel() >= lvl
+}
+
+func (h *fileHandler) Write(rec Record) error {
+	ent := rec.e
+	buf, err := h.encoder.encodeEntry(ent, ent.fields)
+	if err != nil {
+		return err
+	}
+	_, err = h.out.Write(buf)
+	return err
+}
+
+func (h *fileHandler) Close() error {
+	return h.out.Close()
+}
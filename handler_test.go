@@ -0,0 +1,123 @@
+package pine
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedEntry struct {
+	level   Level
+	message string
+	caller  *Caller
+	fields  map[string]interface{}
+}
+
+type recordingHandler struct {
+	entries []recordedEntry
+	closed  bool
+}
+
+func (h *recordingHandler) Enabled(lvl Level) bool {
+	return true
+}
+
+func (h *recordingHandler) Write(rec Record) error {
+	fields := map[string]interface{}{}
+	for _, f := range rec.Fields() {
+		fields[f.Key()] = f.Value()
+	}
+	h.entries = append(h.entries, recordedEntry{level: rec.Level(), message: rec.Message(), caller: rec.Caller(), fields: fields})
+	return nil
+}
+
+func (h *recordingHandler) Close() error {
+	h.closed = true
+	return nil
+}
+
+type failingHandler struct{}
+
+func (failingHandler) Enabled(lvl Level) bool {
+	return true
+}
+
+func (failingHandler) Write(rec Record) error {
+	return errors.New("boom")
+}
+
+func (failingHandler) Close() error {
+	return errors.New("close boom")
+}
+
+func TestLogger_WithHandler(t *testing.T) {
+	getCaller = func(skip int) (pc uintptr, file string, line int, ok bool) {
+		return 0, "logger_test.go", 2, true
+	}
+
+	console := &bytes.Buffer{}
+	audit := &recordingHandler{}
+	errorsOnly := &recordingHandler{}
+	lvl := NewLevelValue(ErrorLevel)
+
+	lgr := New(Output(console), WithClock(newTestClock()), Fields(String("svc", "api")),
+		WithHandler(audit), WithHandler(HandlerWithLevel(errorsOnly, lvl)))
+
+	lgr.Info("hello", Int("i", 1), Bool("ok", true))
+	lgr.Error("failed", Err(errors.New("oops")))
+	lvl.SetLevel(InfoLevel)
+	lgr.With(Float64("f", 1.5)).Info("again")
+	lgr.Close()
+
+	assert.Equal(t, []recordedEntry{
+		{level: InfoLevel, message: "hello", caller: &Caller{File: "logger_test.go", Line: 2}, fields: map[string]interface{}{"i": int64(1), "ok": true, "svc": "api"}},
+		{level: ErrorLevel, message: "failed", caller: &Caller{File: "logger_test.go", Line: 2}, fields: map[string]interface{}{"error": errors.New("oops"), "svc": "api"}},
+		{level: InfoLevel, message: "again", caller: &Caller{File: "logger_test.go", Line: 2}, fields: map[string]interface{}{"f": 1.5, "svc": "api"}},
+	}, audit.entries)
+	assert.Equal(t, 2, len(errorsOnly.entries))
+	assert.Equal(t, "failed", errorsOnly.entries[0].message)
+	assert.True(t, audit.closed)
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF hello i=1 ok=true svc=api\n2022-08-10T21:29:59.123Z ERR failed error=oops svc=api\n2022-08-10T21:29:59.123Z INF again f=1.5E+00 svc=api\n", console.String())
+}
+
+func TestLogger_HandlerErrors(t *testing.T) {
+	errOut := &bytes.Buffer{}
+	lgr := New(Output(&bytes.Buffer{}), ErrOutput(errOut), WithClock(newTestClock()), WithHandler(failingHandler{}))
+	lgr.Info("hello")
+	lgr.Close()
+
+	assert.Equal(t, "2022-08-10 21:29:59.123456789 +0000 UTC write error: boom\npine.failingHandler close error: close boom\n", errOut.String())
+}
+
+type mutatingHandler struct{}
+
+func (mutatingHandler) Enabled(lvl Level) bool {
+	return true
+}
+
+func (mutatingHandler) Write(rec Record) error {
+	fields := rec.Fields()
+	fields[0] = String("i", "changed")
+	rec.Caller().Line = 42
+	return nil
+}
+
+func (mutatingHandler) Close() error {
+	return nil
+}
+
+func TestLogger_HandlerRecordReadOnly(t *testing.T) {
+	getCaller = func(skip int) (pc uintptr, file string, line int, ok bool) {
+		return 0, "logger_test.go", 2, true
+	}
+
+	audit := &recordingHandler{}
+	lgr := New(Output(&bytes.Buffer{}), WithClock(newTestClock()), WithHandler(mutatingHandler{}), WithHandler(audit))
+	lgr.Info("hello", Int("i", 1))
+
+	assert.Equal(t, []recordedEntry{
+		{level: InfoLevel, message: "hello", caller: &Caller{File: "logger_test.go", Line: 2}, fields: map[string]interface{}{"i": int64(1)}},
+	}, audit.entries)
+}
@@ -0,0 +1,78 @@
+package pine
+
+import (
+	"fmt"
+)
+
+// Hook is called with every entry before it is written to the handlers.
+// The hooks of an entry run in the order they were registered and may modify
+// it. Hooks are called concurrently for entries logged by concurrent
+// goroutines. They may log through the logger, e.g. to report a failure, and
+// are then called with that entry as well. The entry must not be retained
+// after Fire returns. A panicking hook is reported to the error output and
+// skipped. Entries are redacted before the hooks run, so the message and
+// fields set by hooks are written as they are.
+type Hook interface {
+	// Levels returns the levels the hook is called for. Nil means all levels.
+	Levels() []Level
+	// Fire is called with the entry. Returning false drops the entry; the
+	// remaining hooks are not called.
+	Fire(ent *Entry) bool
+}
+
+// NewHook returns a hook calling fn for entries of the levels, or of all
+// levels when none are given.
+func NewHook(fn func(ent *Entry) bool, levels ...Level) Hook {
+	return &funcHook{fn: fn, levels: levels}
+}
+
+type funcHook struct {
+	fn     func(ent *Entry) bool
+	levels []Level
+}
+
+func (h *funcHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *funcHook) Fire(ent *Entry) bool {
+	return h.fn(ent)
+}
+
+// runHooks reports whether the entry should be written.
+func (l *Logger) runHooks(e *Entry) bool {
+	for _, h := range l.hooks {
+		if !hookFires(h, e.level) {
+			continue
+		}
+		if !l.fireHook(h, e) {
+			return false
+		}
+	}
+	return true
+}
+
+func hookFires(h Hook, lvl Level) bool {
+	levels := h.Levels()
+	if levels == nil {
+		return true
+	}
+	for _, l := range levels {
+		if l == lvl {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Logger) fireHook(h Hook, e *Entry) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if l.errOut != nil {
+				fmt.Fprintf(l.errOut, "%v %T hook panic: %v\n", e.time, h, r)
+			}
+			ok = true
+		}
+	}()
+	return h.Fire(e)
+}
@@ -0,0 +1,127 @@
+package pine
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Hooks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var order []string
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()),
+		WithHook(NewHook(func(ent *Entry) bool {
+			order = append(order, "first")
+			ent.RemoveField("password")
+			ent.AddFields(String("env", "test"))
+			return true
+		})),
+		WithHook(NewHook(func(ent *Entry) bool {
+			order = append(order, "second")
+			ent.SetMessage(ent.Message() + "!")
+			return true
+		})),
+		Fields(String("service", "api")),
+	)
+
+	lgr.Info("hello", String("password", "secret"), Int("i", 1))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF hello! env=test i=1 service=api\n", buf.String())
+	assert.Equal(t, []string{"first", "second"}, order)
+}
+
+func TestLogger_HookVeto(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var alerts []string
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()),
+		WithHook(NewHook(func(ent *Entry) bool {
+			return ent.Message() != "noise"
+		})),
+		WithHook(NewHook(func(ent *Entry) bool {
+			alerts = append(alerts, ent.Message())
+			for _, f := range ent.Fields() {
+				if f.Key() == "error" {
+					alerts = append(alerts, f.Value().(error).Error())
+				}
+			}
+			return true
+		}, ErrorLevel, FatalLevel)),
+	)
+
+	lgr.Info("noise")
+	lgr.Info("hello")
+	lgr.Error("failed", Err(errors.New("boom")))
+	lgr.Error("noise")
+	assert.Equal(t, ""+
+		"2022-08-10T21:29:59.123Z INF hello\n"+
+		"2022-08-10T21:29:59.123Z ERR failed error=boom\n", buf.String())
+	assert.Equal(t, []string{"failed", "boom"}, alerts)
+}
+
+func TestLogger_HookPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	called := false
+	lgr := New(NoColors(), Output(buf), ErrOutput(errBuf), WithClock(newTestClock()),
+		WithHook(NewHook(func(ent *Entry) bool {
+			panic("hook failed")
+		})),
+		WithHook(NewHook(func(ent *Entry) bool {
+			called = true
+			return true
+		})),
+	)
+
+	assert.NotPanics(t, func() {
+		lgr.Info("hello")
+	})
+	assert.True(t, called)
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF hello\n", buf.String())
+	assert.Equal(t, "2022-08-10 21:29:59.123456789 +0000 UTC *pine.funcHook hook panic: hook failed\n", errBuf.String())
+}
+
+func TestLogger_HookLogs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var lgr *Logger
+	lgr = New(NoColors(), Output(buf), WithClock(newTestClock()),
+		WithHook(NewHook(func(ent *Entry) bool {
+			lgr.Named("audit").Warn("error logged", String("message", ent.Message()))
+			return true
+		}, ErrorLevel)))
+
+	done := make(chan struct{})
+	go func() {
+		lgr.Error("failed")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging from a hook deadlocked")
+	}
+
+	assert.Equal(t, ""+
+		"2022-08-10T21:29:59.123Z WRN [audit] error logged message=failed\n"+
+		"2022-08-10T21:29:59.123Z ERR failed\n", buf.String())
+}
+
+func TestLogger_HookRedacted(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var seen []string
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()),
+		Redact(RedactOptions{Keys: []string{"password"}, Detectors: []Detector{EmailDetector()}}),
+		WithHook(NewHook(func(ent *Entry) bool {
+			seen = append(seen, ent.Message())
+			for _, f := range ent.Fields() {
+				seen = append(seen, f.Key()+"="+f.Value().(string))
+			}
+			return true
+		})),
+	)
+
+	lgr.Info("mail bob@example.com", String("password", "secret"))
+	assert.Equal(t, []string{"mail [REDACTED]", "password=[REDACTED]"}, seen)
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF mail [REDACTED] password=\"[REDACTED]\"\n", buf.String())
+}
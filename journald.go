@@ -0,0 +1,227 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultJournaldSocket is the socket of the native journal protocol.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldOptions configures the journald output.
+type JournaldOptions struct {
+	// SocketPath defaults to DefaultJournaldSocket.
+	SocketPath string
+	// SyslogIdentifier defaults to the name of the executable.
+	SyslogIdentifier string
+}
+
+// JournaldAvailable reports whether the process runs under systemd with a
+// journal accepting native entries.
+func JournaldAvailable() bool {
+	_, err := os.Stat(DefaultJournaldSocket)
+	return err == nil
+}
+
+type journaldHandler struct {
+	level   *LevelValue
+	encoder encoder
+	out     io.WriteCloser
+}
+
+func (h *journaldHandler) levelValue() *LevelValue {
+	return h.level
+}
+
+func (h *journaldHandler) Enabled(lvl Level) bool {
+	return h.level.GetLevel() >= lvl
+}
+
+func (h *journaldHandler) Write(rec Record) error {
+	ent := rec.e
+	buf, err := h.encoder.encodeEntry(ent, ent.fields)
+	if err != nil {
+		return err
+	}
+	_, err = h.out.Write(buf)
+	return err
+}
+
+func (h *journaldHandler) Close() error {
+	return h.out.Close()
+}
+
+// journaldEncoder serializes an entry to the native journal protocol: one
+// KEY=value line per field, or KEY, a little-endian 64 bit length and the
+// value for values containing line breaks.
+type journaldEncoder struct {
+	identifier string
+}
+
+func newJournaldEncoder(opts JournaldOptions) journaldEncoder {
+	identifier := opts.SyslogIdentifier
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	return journaldEncoder{identifier: identifier}
+}
+
+// journaldReserved are the fields set by the encoder. Entry fields with
+// these names get a FIELD_ prefix instead of overriding them.
+var journaldReserved = map[string]struct{}{
+	"MESSAGE":           {},
+	"PRIORITY":          {},
+	"SYSLOG_IDENTIFIER": {},
+	"CODE_FILE":         {},
+	"CODE_LINE":         {},
+	"CODE_FUNC":         {},
+	"LOGGER":            {},
+}
+
+func (l journaldEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	appendJournalField(buf, "MESSAGE", ent.message)
+	appendJournalField(buf, "PRIORITY", strconv.Itoa(int(gelfLevel(ent.level))))
+	appendJournalField(buf, "SYSLOG_IDENTIFIER", l.identifier)
+	if ent.caller != nil {
+		appendJournalField(buf, "CODE_FILE", ent.caller.File)
+		appendJournalField(buf, "CODE_LINE", strconv.Itoa(ent.caller.Line))
+		if fn := runtime.FuncForPC(ent.caller.pc); fn != nil {
+			appendJournalField(buf, "CODE_FUNC", fn.Name())
+		}
+	}
+	if ent.name != "" {
+		appendJournalField(buf, "LOGGER", ent.name)
+	}
+
+	sorted := make([]Field, len(fields))
+	copy(sorted, fields)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].key < sorted[j].key
+	})
+	for _, field := range sorted {
+		if field.tp == objectType || field.tp == arrayType {
+			v, err := marshalField(field)
+			if err != nil {
+				return nil, err
+			}
+			flattenValue(field.key, "_", v, func(key string, leaf interface{}) {
+				appendJournalField(buf, journalKey(key), leafString(leaf))
+			})
+			continue
+		}
+		ok, value, err := getStringValue(field)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			appendJournalField(buf, journalKey(field.key), value)
+		}
+	}
+
+	for i, st := range ent.stacks {
+		appendJournalField(buf, journalKey(stackKey("stack", i)), st.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// journalKey returns the journal field name for key: uppercase letters,
+// digits and underscores, not starting with an underscore or a digit, at most
+// 64 characters.
+func journalKey(key string) string {
+	b := make([]byte, 0, len(key))
+	for i := 0; i < len(key) && len(b) < 64; i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		default:
+			c = '_'
+		}
+		if len(b) == 0 && (c == '_' || c >= '0' && c <= '9') {
+			// leading underscores are reserved for trusted fields
+			continue
+		}
+		b = append(b, c)
+	}
+	name := string(b)
+	if name == "" {
+		return "FIELD"
+	}
+	if _, ok := journaldReserved[name]; ok {
+		return "FIELD_" + name
+	}
+	return name
+}
+
+func appendJournalField(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	b.Write(size[:])
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journaldWriter sends one entry per datagram. Entries exceeding the
+// datagram size are passed as a file descriptor.
+type journaldWriter struct {
+	addr *net.UnixAddr
+
+	mu sync.Mutex
+	// conn is not connected, ancillary data can only be sent with an address
+	conn *net.UnixConn
+}
+
+func newJournaldWriter(path string) *journaldWriter {
+	if path == "" {
+		path = DefaultJournaldSocket
+	}
+	return &journaldWriter{addr: &net.UnixAddr{Name: path, Net: "unixgram"}}
+}
+
+func (w *journaldWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+		if err != nil {
+			return 0, err
+		}
+		w.conn = conn
+	}
+
+	if err := sendJournal(w.conn, w.addr, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *journaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,105 @@
+package pine
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// memfdCreateTrap holds the memfd_create syscall numbers missing from the
+// syscall package.
+var memfdCreateTrap = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+	fAddSeals       = 1033
+	// F_SEAL_SEAL, F_SEAL_SHRINK, F_SEAL_GROW and F_SEAL_WRITE
+	sealAll = 0x1 | 0x2 | 0x4 | 0x8
+)
+
+// sendJournal sends p as a datagram and falls back to a file descriptor when
+// it exceeds the datagram size.
+func sendJournal(conn *net.UnixConn, addr *net.UnixAddr, p []byte) error {
+	_, err := conn.WriteToUnix(p, addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return sendJournalFD(conn, addr, p)
+	}
+	return err
+}
+
+// sendJournalFD passes an entry too large for a datagram as a sealed memfd,
+// or as an unlinked file in /dev/shm when memfds are not supported, like
+// sd_journal_send does.
+func sendJournalFD(conn *net.UnixConn, addr *net.UnixAddr, p []byte) error {
+	f, err := journalMemfd(p)
+	if err != nil {
+		if f, err = journalTempFile(p); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), addr)
+	return err
+}
+
+func journalMemfd(p []byte) (*os.File, error) {
+	trap, ok := memfdCreateTrap[runtime.GOARCH]
+	if !ok {
+		return nil, syscall.ENOSYS
+	}
+	name, err := syscall.BytePtrFromString("pine-journal")
+	if err != nil {
+		return nil, err
+	}
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(name)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	f := os.NewFile(fd, "pine-journal")
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// journald only accepts memfds which can't be modified any more
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, sealAll); errno != 0 {
+		f.Close()
+		return nil, errno
+	}
+	return f, nil
+}
+
+func journalTempFile(p []byte) (*os.File, error) {
+	f, err := ioutil.TempFile("/dev/shm", "pine-journal")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,55 @@
+package pine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournald_LargeEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	w := newJournaldWriter(path)
+	defer w.Close()
+	entry := append([]byte("MESSAGE="), bytes.Repeat([]byte("x"), 4<<20)...)
+	entry = append(entry, '\n')
+	_, err = w.Write(entry)
+	require.NoError(t, err)
+
+	buf := make([]byte, 16)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	require.NoError(t, err)
+	require.Len(t, fds, 1)
+
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, entry, data)
+
+	// the memfd is sealed against modification
+	_, err = f.Write([]byte("y"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package pine
+
+import (
+	"net"
+)
+
+// sendJournal sends p as a datagram. Passing large entries as a file
+// descriptor is only supported on Linux.
+func sendJournal(conn *net.UnixConn, addr *net.UnixAddr, p []byte) error {
+	_, err := conn.WriteToUnix(p, addr)
+	return err
+}
@@ -0,0 +1,109 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournaldEncoder(t *testing.T) {
+	ent := &Entry{level: WarnLevel, time: testDate, message: "disk almost full", name: "storage"}
+	b, err := newJournaldEncoder(JournaldOptions{SyslogIdentifier: "api"}).encodeEntry(ent, []Field{
+		Int("percent", 91),
+		String("mount-point", "/data"),
+		String("message", "shadowed"),
+		Object("user", testUser{ID: 7, Roles: []string{"admin"}}),
+		Err(errors.New("line 1\nline 2")),
+	})
+	require.NoError(t, err)
+
+	errValue := "line 1\nline 2"
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len(errValue)))
+	assert.Equal(t, "MESSAGE=disk almost full\n"+
+		"PRIORITY=4\n"+
+		"SYSLOG_IDENTIFIER=api\n"+
+		"LOGGER=storage\n"+
+		"ERROR\n"+string(size)+errValue+"\n"+
+		"FIELD_MESSAGE=shadowed\n"+
+		"MOUNT_POINT=/data\n"+
+		"PERCENT=91\n"+
+		"USER_ID=7\n"+
+		"USER_ROLES_0=admin\n", string(b))
+}
+
+func TestJournalKey(t *testing.T) {
+	assert.Equal(t, "ORDER_ID", journalKey("order.id"))
+	assert.Equal(t, "ID", journalKey("_id"))
+	assert.Equal(t, "XX", journalKey("1xx"))
+	assert.Equal(t, "FIELD", journalKey("_"))
+	assert.Equal(t, "FIELD_PRIORITY", journalKey("priority"))
+	assert.Len(t, journalKey(string(bytes.Repeat([]byte("a"), 100))), 64)
+}
+
+func TestJournald(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	defer func(fn func(int) (uintptr, string, int, bool)) { getCaller = fn }(getCaller)
+	getCaller = func(int) (uintptr, string, int, bool) {
+		return 0, "/src/app/main.go", 12, true
+	}
+
+	lgr := New(NoColors(), Output(&bytes.Buffer{}), WithClock(newTestClock()),
+		Journald(JournaldOptions{SocketPath: path, SyslogIdentifier: "api"}), JournaldLevel(InfoLevel))
+	lgr.Debug("hidden")
+	lgr.Error("failed", String("order_id", "7"))
+
+	buf := make([]byte, 4096)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "MESSAGE=failed\n"+
+		"PRIORITY=3\n"+
+		"SYSLOG_IDENTIFIER=api\n"+
+		"CODE_FILE=main.go\n"+
+		"CODE_LINE=12\n"+
+		"ORDER_ID=7\n", string(buf[:n]))
+}
+
+func TestJournald_Sampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	defer func(fn func(int) (uintptr, string, int, bool)) { getCaller = fn }(getCaller)
+	getCaller = func(int) (uintptr, string, int, bool) {
+		return 0, "", 0, false
+	}
+
+	lgr := New(NoColors(), Output(&bytes.Buffer{}), WithClock(newTestClock()),
+		Journald(JournaldOptions{SocketPath: path, SyslogIdentifier: "api"}), JournaldSampling(SampleAll(time.Hour, 1, 0)))
+	for i := 0; i < 3; i++ {
+		lgr.Error("failed")
+	}
+	lgr.Close()
+
+	buf := make([]byte, 4096)
+	var received []string
+	for i := 0; i < 2; i++ {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		received = append(received, string(buf[:n]))
+	}
+	assert.Equal(t, []string{
+		"MESSAGE=failed\nPRIORITY=3\nSYSLOG_IDENTIFIER=api\n",
+		"MESSAGE=suppressed 2 similar messages\nPRIORITY=3\nSYSLOG_IDENTIFIER=api\nSAMPLED_MESSAGE=failed\n",
+	}, received)
+}
@@ -0,0 +1,207 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const hexDigits = "0123456789abcdef"
+
+type jsonEncoder struct {
+	*encoderConfig
+	keys JSONKeys
+}
+
+func newJSONEncoder(config encoderConfig) jsonEncoder {
+	return jsonEncoder{encoderConfig: &config, keys: config.JSONKeys.withDefaults()}
+}
+
+func (l jsonEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
+	buf := newBuffer()
+	defer func() {
+		bufPool.Put(buf)
+	}()
+
+	buf.WriteByte('{')
+	appendJSONKey(buf, l.keys.Time, true)
+	appendJSONString(buf, ent.time.Format("2006-01-02T15:04:05.000Z07:00"))
+	appendJSONKey(buf, l.keys.Level, false)
+	appendJSONString(buf, ent.level.String())
+	if l.ReportCaller && ent.caller != nil {
+		appendJSONKey(buf, l.keys.Caller, false)
+		appendJSONString(buf, fmt.Sprintf("%s:%v", ent.caller.File, ent.caller.Line))
+	}
+	if ent.name != "" {
+		appendJSONKey(buf, l.keys.Logger, false)
+		appendJSONString(buf, ent.name)
+	}
+	appendJSONKey(buf, l.keys.Message, false)
+	appendJSONString(buf, ent.message)
+
+	// the first occurrence of a key wins: entry fields precede logger fields
+	seen := map[string]struct{}{
+		l.keys.Time:    {},
+		l.keys.Level:   {},
+		l.keys.Message: {},
+		l.keys.Stack:   {},
+	}
+	if l.ReportCaller && ent.caller != nil {
+		seen[l.keys.Caller] = struct{}{}
+	}
+	if ent.name != "" {
+		seen[l.keys.Logger] = struct{}{}
+	}
+	unique := make([]Field, 0, len(fields))
+	for i := range fields {
+		if _, ok := seen[fields[i].key]; ok {
+			continue
+		}
+		seen[fields[i].key] = struct{}{}
+		unique = append(unique, fields[i])
+	}
+
+	if !l.DisableSorting {
+		sort.SliceStable(unique, func(i, j int) bool {
+			return unique[i].key < unique[j].key
+		})
+	}
+
+	for i := range unique {
+		if err := l.appendField(buf, unique[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, st := range ent.stacks {
+		appendJSONKey(buf, stackKey(l.keys.Stack, i), false)
+		appendJSONString(buf, flattenStack(st))
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+func (l jsonEncoder) appendField(b *bytes.Buffer, field Field) error {
+	switch field.tp {
+	case stringType:
+		appendJSONKey(b, field.key, false)
+		appendJSONString(b, field.string)
+	case intType, int8Type, int16Type, int32Type, int64Type:
+		appendJSONKey(b, field.key, false)
+		b.WriteString(strconv.FormatInt(field.int64, 10))
+	case boolType:
+		appendJSONKey(b, field.key, false)
+		b.WriteString(strconv.FormatBool(field.int64 == 1))
+	case float32Type:
+		appendJSONKey(b, field.key, false)
+		appendJSONFloat(b, field.float64, 32)
+	case float64Type:
+		appendJSONKey(b, field.key, false)
+		appendJSONFloat(b, field.float64, 64)
+	case timeType:
+		appendJSONKey(b, field.key, false)
+		appendJSONString(b, field.value.(time.Time).Format(time.RFC3339Nano))
+	case jsonType:
+		bts, err := json.Marshal(field.value)
+		if err != nil {
+			return err
+		}
+		appendJSONKey(b, field.key, false)
+		b.Write(bts)
+	case interfaceType:
+		appendJSONKey(b, field.key, false)
+		appendJSONString(b, fmt.Sprint(field.value))
+	case objectType, arrayType:
+		v, err := marshalField(field)
+		if err != nil {
+			return err
+		}
+		appendJSONKey(b, field.key, false)
+		appendJSONValue(b, v)
+	case errorType:
+		if field.err == nil {
+			return nil
+		}
+		appendJSONKey(b, field.key, false)
+		appendJSONString(b, field.err.Error())
+	default:
+		return errors.New("unknown field type")
+	}
+	return nil
+}
+
+func appendJSONKey(b *bytes.Buffer, key string, first bool) {
+	if !first {
+		b.WriteByte(',')
+	}
+	appendJSONString(b, key)
+	b.WriteByte(':')
+}
+
+// appendJSONFloat writes NaN and infinities as strings since JSON has no
+// representation for them.
+func appendJSONFloat(b *bytes.Buffer, f float64, bitSize int) {
+	switch {
+	case math.IsNaN(f):
+		b.WriteString(`"NaN"`)
+	case math.IsInf(f, 1):
+		b.WriteString(`"+Inf"`)
+	case math.IsInf(f, -1):
+		b.WriteString(`"-Inf"`)
+	default:
+		b.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+}
+
+func appendJSONString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+				b.WriteString(`\r`)
+			case '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteString(`\u00`)
+				b.WriteByte(hexDigits[c>>4])
+				b.WriteByte(hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteString(s[start:i])
+			b.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b.WriteString(s[start:])
+	b.WriteByte('"')
+}
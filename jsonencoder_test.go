@@ -0,0 +1,106 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(WithFormat(JSONFormat), Output(buf), WithClock(newTestClock()), WithLevel(TraceLevel), WithStackTraceLevel(DisabledLevel))
+
+	t.Run("message", func(tt *testing.T) {
+		lgr.Info("hello")
+		assert.Equal(tt, `{"time":"2022-08-10T21:29:59.123Z","level":"info","message":"hello"}`+"\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("fields", func(tt *testing.T) {
+		obj := struct {
+			A string `json:"A"`
+		}{A: "B"}
+
+		lgr.Trace("hello",
+			Int("int", 1),
+			Int64("int64", 5),
+			Float32("float32", 6.1),
+			Float64("float64", 7.2),
+			String("string", "s\"q\n"),
+			Time("at", testDate),
+			Err(fmt.Errorf("test error")),
+			Json("json", obj),
+			Interface("obj", obj),
+			Bool("bool", true),
+		)
+
+		assert.Equal(tt, `{"time":"2022-08-10T21:29:59.123Z","level":"trace","message":"hello","at":"2022-08-10T21:29:59.123456789Z","bool":true,"error":"test error","float32":6.1,"float64":7.2,"int":1,"int64":5,"json":{"A":"B"},"obj":"{B}","string":"s\"q\n"}`+"\n", buf.String())
+		assert.True(tt, json.Valid(buf.Bytes()))
+		buf.Reset()
+	})
+
+	t.Run("duplicate keys", func(tt *testing.T) {
+		lgr2 := lgr.With(String("A", "logger"))
+		lgr2.Info("hello", String("A", "entry"), String("message", "override"))
+		assert.Equal(tt, `{"time":"2022-08-10T21:29:59.123Z","level":"info","message":"hello","A":"entry"}`+"\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("non finite floats", func(tt *testing.T) {
+		lgr.Info("hello", Float64("nan", math.NaN()), Float64("inf", math.Inf(1)))
+		assert.Equal(tt, `{"time":"2022-08-10T21:29:59.123Z","level":"info","message":"hello","inf":"+Inf","nan":"NaN"}`+"\n", buf.String())
+		buf.Reset()
+	})
+}
+
+func TestJSONEncoder_Keys(t *testing.T) {
+	getCaller = func(skip int) (pc uintptr, file string, line int, ok bool) {
+		return 0, "logger_test.go", 2, true
+	}
+
+	buf := &bytes.Buffer{}
+	lgr := New(WithFormat(JSONFormat), AddCaller(), Output(buf), WithClock(newTestClock()),
+		WithJSONKeys(JSONKeys{Time: "ts", Level: "severity", Message: "msg"}))
+	lgr.Info("hello", Int("i", 1))
+	assert.Equal(t, `{"ts":"2022-08-10T21:29:59.123Z","severity":"info","caller":"logger_test.go:2","msg":"hello","i":1}`+"\n", buf.String())
+}
+
+func TestJSONEncoder_Stack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(WithFormat(JSONFormat), Output(buf), WithClock(newTestClock()))
+	lgr.Error("hello", Err(outer()))
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "test", got["error"])
+	assert.Contains(t, got["stack"], "inner() at stacktrace_test.go:10 <- outer() at stacktrace_test.go:6")
+}
+
+func TestEnvFormat(t *testing.T) {
+	defer os.Unsetenv("PINE_FORMAT")
+	require.NoError(t, os.Setenv("PINE_FORMAT", "json"))
+
+	buf := &bytes.Buffer{}
+	lgr := New(Output(buf), WithClock(newTestClock()))
+	lgr.Info("hello")
+	assert.Equal(t, `{"time":"2022-08-10T21:29:59.123Z","level":"info","message":"hello"}`+"\n", buf.String())
+}
+
+func TestFormatParse(t *testing.T) {
+	format, err := ParseFormat("JSON")
+	assert.NoError(t, err)
+	assert.Equal(t, JSONFormat, format)
+
+	format, err = ParseFormat("console")
+	assert.NoError(t, err)
+	assert.Equal(t, ConsoleFormat, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package pine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxLevelRequestSize limits the body of a level change request.
+const maxLevelRequestSize = 1 << 12
+
+// LevelHandler returns an http.Handler to inspect and change levels at
+// runtime. Levels are identified by their name, or by their position when
+// they have none.
+//
+// GET responds with the current levels, e.g. {"console":"info","graylog":"warn"}.
+//
+// PUT and POST change a level with a body like
+// {"name":"graylog","level":"trace","ttl":"10m"}. Without a name all levels
+// are changed. With a ttl the previous level is restored when it expires,
+// unless the level was changed by other means in the meantime. Bodies larger
+// than 4 KiB are rejected.
+//
+// Use Logger.LevelValues for the levels of a logger's outputs. LevelHandler
+// panics when different levels have the same name.
+func LevelHandler(lvls ...*LevelValue) http.Handler {
+	h := &levelHandler{
+		levels:  map[string]*LevelValue{},
+		reverts: map[string]*levelRevert{},
+	}
+	for i, lvl := range lvls {
+		name := lvl.Name()
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		if prev, ok := h.levels[name]; ok {
+			if prev == lvl {
+				continue
+			}
+			panic(fmt.Sprintf("pine: duplicate level name %q", name))
+		}
+		h.names = append(h.names, name)
+		h.levels[name] = lvl
+	}
+	return h
+}
+
+// LevelValues returns the levels of the outputs of the logger, e.g. console
+// and graylog, in the order of the outputs. Outputs sharing a level are
+// reported once.
+func (l *Logger) LevelValues() []*LevelValue {
+	var lvls []*LevelValue
+	seen := map[*LevelValue]struct{}{}
+	for _, h := range l.handlers {
+		lh, ok := h.(leveler)
+		if !ok || lh.levelValue() == nil {
+			continue
+		}
+		lvl := lh.levelValue()
+		if _, ok := seen[lvl]; ok {
+			continue
+		}
+		seen[lvl] = struct{}{}
+		lvls = append(lvls, lvl)
+	}
+	return lvls
+}
+
+type levelHandler struct {
+	names  []string
+	levels map[string]*LevelValue
+
+	mu      sync.Mutex
+	reverts map[string]*levelRevert
+}
+
+type levelRevert struct {
+	timer *time.Timer
+	// level is restored unless the level was changed from set meanwhile
+	level Level
+	set   Level
+}
+
+type levelRequest struct {
+	Name  string `json:"name"`
+	Level *Level `json:"level"`
+	TTL   string `json:"ttl"`
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		status, err := h.update(w, r)
+		if err != nil {
+			writeLevelError(w, status, err)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.current())
+}
+
+func (h *levelHandler) update(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req levelRequest
+	body := http.MaxBytesReader(w, r.Body, maxLevelRequestSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid request: %v", err)
+	}
+	if req.Level == nil {
+		return http.StatusBadRequest, fmt.Errorf("level is required")
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			return http.StatusBadRequest, fmt.Errorf("invalid ttl: %q", req.TTL)
+		}
+	}
+
+	names := h.names
+	if req.Name != "" {
+		if _, ok := h.levels[req.Name]; !ok {
+			return http.StatusNotFound, fmt.Errorf("unknown level: %q", req.Name)
+		}
+		names = []string{req.Name}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, name := range names {
+		h.set(name, *req.Level, ttl)
+	}
+	return http.StatusOK, nil
+}
+
+// set must be called with h.mu held.
+func (h *levelHandler) set(name string, lvl Level, ttl time.Duration) {
+	lv := h.levels[name]
+
+	// a pending revert keeps the level from before the first temporary change
+	previous := lv.GetLevel()
+	if rv, ok := h.reverts[name]; ok {
+		rv.timer.Stop()
+		previous = rv.level
+		delete(h.reverts, name)
+	}
+
+	lv.SetLevel(lvl)
+	if ttl == 0 {
+		return
+	}
+
+	rv := &levelRevert{level: previous, set: lvl}
+	rv.timer = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.reverts[name] != rv {
+			return
+		}
+		delete(h.reverts, name)
+		if lv.GetLevel() == rv.set {
+			lv.SetLevel(rv.level)
+		}
+	})
+	h.reverts[name] = rv
+}
+
+func (h *levelHandler) current() map[string]Level {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	levels := make(map[string]Level, len(h.levels))
+	for name, lvl := range h.levels {
+		levels[name] = lvl.GetLevel()
+	}
+	return levels
+}
+
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,116 @@
+package pine
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveLevel(h http.Handler, method, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, "/level", strings.NewReader(body)))
+	return rec
+}
+
+func TestLevelHandler(t *testing.T) {
+	console := NewNamedLevelValue("console", InfoLevel)
+	graylog := NewNamedLevelValue("graylog", WarnLevel)
+	h := LevelHandler(console, graylog, NewLevelValue(ErrorLevel))
+
+	rec := serveLevel(h, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"console":"info","graylog":"warn","2":"error"}`, rec.Body.String())
+
+	rec = serveLevel(h, http.MethodPut, `{"name":"graylog","level":"trace"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"console":"info","graylog":"trace","2":"error"}`, rec.Body.String())
+
+	rec = serveLevel(h, http.MethodPost, `{"level":"debug"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"console":"debug","graylog":"debug","2":"debug"}`, rec.Body.String())
+}
+
+func TestLevelHandler_DuplicateNames(t *testing.T) {
+	console := NewNamedLevelValue("console", InfoLevel)
+	h := LevelHandler(console, console)
+	assert.JSONEq(t, `{"console":"info"}`, serveLevel(h, http.MethodGet, "").Body.String())
+
+	assert.PanicsWithValue(t, `pine: duplicate level name "console"`, func() {
+		LevelHandler(console, NewNamedLevelValue("console", DebugLevel))
+	})
+}
+
+func TestLogger_LevelValues(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "app.log")
+	lgr := New(Output(&bytes.Buffer{}), WithLevel(InfoLevel), File(path, RotateOptions{}), FileLevel(WarnLevel),
+		WithHandler(&recordingHandler{}))
+	defer lgr.Close()
+
+	lvls := lgr.LevelValues()
+	assert.Len(t, lvls, 2)
+
+	h := LevelHandler(lvls...)
+	assert.JSONEq(t, `{"console":"info","file":"warn"}`, serveLevel(h, http.MethodGet, "").Body.String())
+
+	serveLevel(h, http.MethodPut, `{"name":"file","level":"debug"}`)
+	assert.Equal(t, DebugLevel, lgr.handlers[1].(*fileHandler).level.GetLevel())
+}
+
+func TestLevelHandler_TTL(t *testing.T) {
+	console := NewNamedLevelValue("console", InfoLevel)
+	h := LevelHandler(console)
+
+	rec := serveLevel(h, http.MethodPut, `{"name":"console","level":"trace","ttl":"50ms"}`)
+	assert.JSONEq(t, `{"console":"trace"}`, rec.Body.String())
+	rec = serveLevel(h, http.MethodPut, `{"name":"console","level":"debug","ttl":"50ms"}`)
+	assert.JSONEq(t, `{"console":"debug"}`, rec.Body.String())
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(serveLevel(h, http.MethodGet, "").Body.String(), `"info"`)
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestLevelHandler_Errors(t *testing.T) {
+	h := LevelHandler(NewNamedLevelValue("console", InfoLevel))
+
+	check := func(method, body string, status int, msg string) {
+		rec := serveLevel(h, method, body)
+		assert.Equal(t, status, rec.Code)
+		assert.Contains(t, rec.Body.String(), msg)
+	}
+
+	check(http.MethodPut, `{"name":"console","level":"loud"}`, http.StatusBadRequest, "invalid level")
+	check(http.MethodPut, `{"name":"console"}`, http.StatusBadRequest, "level is required")
+	check(http.MethodPut, `{"level":"info","ttl":"soon"}`, http.StatusBadRequest, "invalid ttl")
+	check(http.MethodPut, `{"name":"gelf","level":"info"}`, http.StatusNotFound, "unknown level")
+	check(http.MethodDelete, ``, http.StatusMethodNotAllowed, "not allowed")
+}
+
+func TestLevelHandler_TTLChangedMeanwhile(t *testing.T) {
+	console := NewNamedLevelValue("console", InfoLevel)
+	h := LevelHandler(console)
+
+	serveLevel(h, http.MethodPut, `{"name":"console","level":"trace","ttl":"20ms"}`)
+	console.SetLevel(WarnLevel)
+
+	time.Sleep(time.Millisecond * 60)
+	assert.Equal(t, WarnLevel, console.GetLevel())
+}
+
+func TestLevelHandler_BodyLimit(t *testing.T) {
+	h := LevelHandler(NewNamedLevelValue("console", InfoLevel))
+
+	body := `{"name":"console","level":"debug","ttl":"` + strings.Repeat("1", maxLevelRequestSize) + `s"}`
+	rec := serveLevel(h, http.MethodPut, body)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "request body too large")
+}
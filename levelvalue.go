@@ -1,17 +1,89 @@
 package pine
 
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelValue is a level which can be changed at runtime, safely for
+// concurrent use. It can be bound to flags and decoded from text or JSON.
 type LevelValue struct {
-	Value *Level
+	level int32
+	name  string
+
+	mu        sync.Mutex
+	listeners []func(old, new Level)
 }
 
 func NewLevelValue(lvl Level) *LevelValue {
-	return &LevelValue{Value: &lvl}
+	return &LevelValue{level: int32(lvl)}
+}
+
+// NewNamedLevelValue returns a LevelValue reported under name by LevelHandler.
+func NewNamedLevelValue(name string, lvl Level) *LevelValue {
+	return &LevelValue{level: int32(lvl), name: name}
+}
+
+func (l *LevelValue) SetLevel(lvl Level) {
+	old := Level(atomic.SwapInt32(&l.level, int32(lvl)))
+	if old == lvl {
+		return
+	}
+
+	l.mu.Lock()
+	listeners := l.listeners
+	l.mu.Unlock()
+	for _, fn := range listeners {
+		fn(old, lvl)
+	}
+}
+
+func (l *LevelValue) GetLevel() Level {
+	return Level(atomic.LoadInt32(&l.level))
+}
+
+func (l *LevelValue) Name() string {
+	return l.name
+}
+
+// OnChange registers fn to be called after every change of the level. It is
+// called synchronously by the goroutine changing the level.
+func (l *LevelValue) OnChange(fn func(old, new Level)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners[:len(l.listeners):len(l.listeners)], fn)
+}
+
+func (l *LevelValue) String() string {
+	return l.GetLevel().String()
+}
+
+// Set implements flag.Value.
+func (l *LevelValue) Set(text string) error {
+	return l.UnmarshalText([]byte(text))
+}
+
+func (l *LevelValue) MarshalText() ([]byte, error) {
+	return l.GetLevel().MarshalText()
 }
 
-func (l LevelValue) SetLevel(lvl Level) {
-	*l.Value = lvl
+func (l *LevelValue) UnmarshalText(text []byte) error {
+	var lvl Level
+	if err := lvl.UnmarshalText(text); err != nil {
+		return err
+	}
+	l.SetLevel(lvl)
+	return nil
 }
 
-func (l LevelValue) GetLevel() Level {
-	return *l.Value
+func (l *LevelValue) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return l.UnmarshalText([]byte(text))
 }
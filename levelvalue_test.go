@@ -0,0 +1,75 @@
+package pine
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelValue_Concurrent(t *testing.T) {
+	lvl := NewLevelValue(InfoLevel)
+	lgr := New(Output(ioutil.Discard), WithLevelValue(lvl))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			lvl.SetLevel(Level(i%int(TraceLevel)) + 1)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			lgr.Debug("hello")
+		}
+	}()
+	wg.Wait()
+}
+
+func TestLevelValue_OnChange(t *testing.T) {
+	lvl := NewLevelValue(InfoLevel)
+	var changes [][2]Level
+	lvl.OnChange(func(old, new Level) {
+		changes = append(changes, [2]Level{old, new})
+	})
+
+	lvl.SetLevel(DebugLevel)
+	lvl.SetLevel(DebugLevel)
+	lvl.SetLevel(ErrorLevel)
+	assert.Equal(t, [][2]Level{{InfoLevel, DebugLevel}, {DebugLevel, ErrorLevel}}, changes)
+}
+
+func TestLevelValue_Flag(t *testing.T) {
+	lvl := NewLevelValue(InfoLevel)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(lvl, "level", "log level")
+
+	require.NoError(t, fs.Parse([]string{"-level", "trace"}))
+	assert.Equal(t, TraceLevel, lvl.GetLevel())
+	assert.Equal(t, "trace", lvl.String())
+	assert.Error(t, fs.Parse([]string{"-level", "loud"}))
+}
+
+func TestLevelValue_JSON(t *testing.T) {
+	var cfg struct {
+		Level *LevelValue `json:"level"`
+	}
+	cfg.Level = NewLevelValue(InfoLevel)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"level":"warn"}`), &cfg))
+	assert.Equal(t, WarnLevel, cfg.Level.GetLevel())
+
+	b, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `{"level":"warn"}`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"level":"loud"}`), &cfg))
+	assert.Error(t, json.Unmarshal([]byte(`{"level":3}`), &cfg))
+}
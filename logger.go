@@ -1,22 +1,34 @@
 package pine
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/go-pckg/pine/file"
 	"github.com/go-pckg/pine/gelf"
 )
 
 const defaultFramesToSkip = 4
 
+// defaultFlushTimeout bounds how long Close waits for queued entries.
+const defaultFlushTimeout = 5 * time.Second
+
 type consoleConfig struct {
+	disabled      bool
+	format        Format
 	encoderConfig encoderConfig
 	level         *LevelValue
 	out           io.Writer
+	sampling      *SamplingOptions
 }
 
 type gelfConfig struct {
@@ -24,37 +36,114 @@ type gelfConfig struct {
 	Addr        string
 	Level       *LevelValue
 	ExtraFields map[string]Field
+
+	ChunkSize   int
+	Compression gelf.CompressionType
+	TLSConfig   *tls.Config
+	Async       *AsyncOptions
+	Sampling    *SamplingOptions
+
+	FieldOptions   GelfFieldOptions
+	MessageOptions GelfMessageOptions
+	NoFullMessage  bool
+
+	tlsErr error
+}
+
+type fileConfig struct {
+	Enabled  bool
+	Path     string
+	Rotate   RotateOptions
+	Level    *LevelValue
+	Format   Format
+	Sampling *SamplingOptions
+}
+
+type syslogConfig struct {
+	Enabled  bool
+	Addr     string
+	Level    *LevelValue
+	Options  SyslogOptions
+	Sampling *SamplingOptions
+}
+
+type journaldConfig struct {
+	Enabled  bool
+	Level    *LevelValue
+	Options  JournaldOptions
+	Sampling *SamplingOptions
 }
 
 type config struct {
-	consoleConfig consoleConfig
-	gelfConfig    gelfConfig
+	consoleConfig  consoleConfig
+	gelfConfig     gelfConfig
+	fileConfig     fileConfig
+	syslogConfig   syslogConfig
+	journaldConfig journaldConfig
 
 	stackTraceLevel *LevelValue
+	stackExtractors []StackExtractor
+	captureStack    bool
+	errorDetails    ErrorDetail
 	errOut          io.Writer
 	clock           Clock
 	fields          map[string]Field
+	handlers        []Handler
+	hooks           []Hook
+	redactor        *redactor
+	levels          *LevelRegistry
+	// defaultLevels are the levels of outputs following the default level
+	// rather than a level of their own
+	defaultLevels []*LevelValue
+
+	contextExtractors []ContextExtractor
+
+	exitFunc       func(code int)
+	panicFunc      func(msg string)
+	noPanicAndExit bool
+	flushTimeout   time.Duration
 }
 
 func New(options ...Option) *Logger {
+	defaultLevel, levels := readEnvLevels("PINE_LEVEL", DebugLevel)
 	cfg := config{
 		consoleConfig: consoleConfig{
+			format: readEnvOrDefaultFormat("PINE_FORMAT", ConsoleFormat),
 			encoderConfig: encoderConfig{
 				UseColors: readEnvOrDefaultUseColors(false),
 			},
-			level: NewLevelValue(readEnvOrDefaultLevel("PINE_LEVEL", DebugLevel)),
+			level: NewNamedLevelValue("console", defaultLevel),
 			out:   os.Stderr,
 		},
 		gelfConfig: gelfConfig{
 			Enabled:     readEnvOrDefaultBool("PINE_GRAYLOG_ENABLED", false),
-			Level:       NewLevelValue(readEnvOrDefaultLevel("PINE_GRAYLOG_LEVEL", readEnvOrDefaultLevel("PINE_LEVEL", DebugLevel))),
 			Addr:        readEnvOrDefaultString("PINE_GRAYLOG_ADDR", ""),
 			ExtraFields: readGraylogExtraFields("PINE_GRAYLOG_EXTRA_"),
+			ChunkSize:   gelf.DefaultChunkSize,
+			Compression: gelf.CompressGzip,
+
+			MessageOptions: defaultGelfMessageOptions,
+		},
+		fileConfig: fileConfig{
+			Format: readEnvOrDefaultFormat("PINE_FORMAT", ConsoleFormat),
 		},
 		errOut:          os.Stderr,
 		clock:           DefaultClock,
 		stackTraceLevel: NewLevelValue(ErrorLevel),
+		stackExtractors: defaultStackExtractors,
 		fields:          map[string]Field{},
+		levels:          levels,
+		exitFunc:        os.Exit,
+		panicFunc:       defaultPanicFunc,
+		flushTimeout:    defaultFlushTimeout,
+	}
+	cfg.gelfConfig.Level = cfg.outputLevel("graylog", "PINE_GRAYLOG_LEVEL", defaultLevel)
+	cfg.fileConfig.Level = cfg.outputLevel("file", "PINE_FILE_LEVEL", defaultLevel)
+	cfg.syslogConfig.Level = cfg.outputLevel("syslog", "PINE_SYSLOG_LEVEL", defaultLevel)
+	cfg.journaldConfig.Level = cfg.outputLevel("journald", "PINE_JOURNALD_LEVEL", defaultLevel)
+	cfg.gelfConfig.TLSConfig, cfg.gelfConfig.tlsErr = readEnvTLSConfig("PINE_GRAYLOG_TLS_")
+	if readEnvOrDefaultBool("PINE_GRAYLOG_ASYNC", false) {
+		cfg.gelfConfig.Async = &AsyncOptions{}
 	}
 
 	for _, opt := range options {
@@ -64,61 +153,185 @@ func New(options ...Option) *Logger {
 	return create(cfg)
 }
 
+// outputLevel returns the level of an output read from the key, or the
+// default level when the key is not set.
+func (c *config) outputLevel(name, key string, defaultLevel Level) *LevelValue {
+	if os.Getenv(key) != "" {
+		return NewNamedLevelValue(name, readEnvOrDefaultLevel(key, defaultLevel))
+	}
+	lvl := NewNamedLevelValue(name, defaultLevel)
+	c.defaultLevels = append(c.defaultLevels, lvl)
+	return lvl
+}
+
 func create(cfg config) *Logger {
-	handlers := []handler{
-		&consoleHandler{
+	var handlers []Handler
+	if !cfg.consoleConfig.disabled {
+		handlers = append(handlers, withSampling(&consoleHandler{
 			level:   cfg.consoleConfig.level,
-			encoder: newConsoleEncoder(cfg.consoleConfig.encoderConfig),
+			encoder: newEncoder(cfg.consoleConfig.format, cfg.consoleConfig.encoderConfig),
 			out:     cfg.consoleConfig.out,
-		},
+		}, cfg.consoleConfig.sampling))
+	}
+	if cfg.gelfConfig.TLSConfig != nil && strings.HasPrefix(cfg.gelfConfig.Addr, "udp://") && cfg.gelfConfig.tlsErr == nil {
+		cfg.gelfConfig.tlsErr = errors.New("TLS is not supported by the UDP transport")
 	}
-	if cfg.gelfConfig.Enabled {
-		handlers = append(handlers, &gelfHandler{
+	if cfg.gelfConfig.Enabled && cfg.gelfConfig.tlsErr != nil {
+		// never fall back to a plain text connection
+		if cfg.errOut != nil {
+			fmt.Fprintf(cfg.errOut, "gelf tls config error: %v\n", cfg.gelfConfig.tlsErr)
+		}
+	} else if cfg.gelfConfig.Enabled {
+		out := newGelfWriter(cfg.gelfConfig)
+		if cfg.gelfConfig.Async != nil {
+			out = newAsyncWriter(out, cfg.errOut, *cfg.gelfConfig.Async)
+		}
+		msgOpts := cfg.gelfConfig.MessageOptions
+		if cfg.gelfConfig.NoFullMessage {
+			msgOpts.FullMessage = 0
+		}
+		handlers = append(handlers, withSampling(&gelfHandler{
 			level:   cfg.gelfConfig.Level,
-			encoder: newGelfEncoder(cfg.gelfConfig.ExtraFields),
-			out:     gelf.NewTCPWriter(cfg.gelfConfig.Addr),
-			errOut:  cfg.errOut,
-		})
+			encoder: newGelfEncoder(cfg.gelfConfig.ExtraFields, cfg.gelfConfig.FieldOptions, msgOpts),
+			out:     out,
+		}, cfg.gelfConfig.Sampling))
+	}
+	if cfg.fileConfig.Enabled {
+		encCfg := cfg.consoleConfig.encoderConfig
+		encCfg.UseColors = false
+		rotate := cfg.fileConfig.Rotate
+		if rotate.ErrorOutput == nil {
+			rotate.ErrorOutput = cfg.errOut
+			if rotate.ErrorOutput == nil {
+				rotate.ErrorOutput = ioutil.Discard
+			}
+		}
+		handlers = append(handlers, withSampling(&fileHandler{
+			level:   cfg.fileConfig.Level,
+			encoder: newEncoder(cfg.fileConfig.Format, encCfg),
+			out:     file.NewWriter(cfg.fileConfig.Path, rotate),
+		}, cfg.fileConfig.Sampling))
+	}
+	if cfg.syslogConfig.Enabled {
+		handlers = append(handlers, withSampling(&syslogHandler{
+			level:   cfg.syslogConfig.Level,
+			encoder: newSyslogEncoder(cfg.syslogConfig.Options, cfg.consoleConfig.encoderConfig),
+			out:     newSyslogWriter(cfg.syslogConfig.Addr, cfg.syslogConfig.Options.TLSConfig),
+		}, cfg.syslogConfig.Sampling))
+	}
+	if cfg.journaldConfig.Enabled {
+		handlers = append(handlers, withSampling(&journaldHandler{
+			level:   cfg.journaldConfig.Level,
+			encoder: newJournaldEncoder(cfg.journaldConfig.Options),
+			out:     newJournaldWriter(cfg.journaldConfig.Options.SocketPath),
+		}, cfg.journaldConfig.Sampling))
 	}
+	handlers = append(handlers, cfg.handlers...)
 
 	lgr := &Logger{
 		handlers:        handlers,
+		hooks:           cfg.hooks,
+		redactor:        cfg.redactor,
+		levels:          cfg.levels,
 		errOut:          cfg.errOut,
 		clock:           cfg.clock,
 		lock:            &sync.Mutex{},
 		fields:          cfg.fields,
 		stackTraceLevel: cfg.stackTraceLevel,
+		stackExtractors: cfg.stackExtractors,
+		captureStack:    cfg.captureStack,
+		errorDetails:    cfg.errorDetails,
+		defaultLevels:   map[*LevelValue]struct{}{cfg.consoleConfig.level: {}},
+
+		contextExtractors: cfg.contextExtractors,
+
+		exitFunc:       cfg.exitFunc,
+		panicFunc:      cfg.panicFunc,
+		noPanicAndExit: cfg.noPanicAndExit,
+		flushTimeout:   cfg.flushTimeout,
+	}
+	for _, lvl := range cfg.defaultLevels {
+		lgr.defaultLevels[lvl] = struct{}{}
 	}
 
 	return lgr
 }
 
+// newGelfWriter creates the transport for the address scheme: udp://host:port,
+// tcp://host:port or tls://host:port. Addresses without a scheme use TCP, or
+// TLS when a TLS config is set.
+func newGelfWriter(cfg gelfConfig) io.WriteCloser {
+	addr := cfg.Addr
+	switch {
+	case strings.HasPrefix(addr, "udp://"):
+		w := gelf.NewUDPWriter(strings.TrimPrefix(addr, "udp://"))
+		w.ChunkSize = cfg.ChunkSize
+		w.CompressionType = cfg.Compression
+		return w
+	case strings.HasPrefix(addr, "tls://"):
+		return gelf.NewTLSWriter(strings.TrimPrefix(addr, "tls://"), cfg.TLSConfig)
+	case cfg.TLSConfig != nil:
+		return gelf.NewTLSWriter(strings.TrimPrefix(addr, "tcp://"), cfg.TLSConfig)
+	default:
+		return gelf.NewTCPWriter(strings.TrimPrefix(addr, "tcp://"))
+	}
+}
+
 type Logger struct {
 	stackTraceLevel *LevelValue
-
-	handlers []handler
+	stackExtractors []StackExtractor
+	captureStack    bool
+	errorDetails    ErrorDetail
+
+	name     string
+	handlers []Handler
+	hooks    []Hook
+	redactor *redactor
+	levels   *LevelRegistry
+	// defaultLevels are the output levels replaced by levels of named loggers
+	defaultLevels map[*LevelValue]struct{}
 
 	errOut io.Writer
 	lock   *sync.Mutex
 	clock  Clock
 	fields map[string]Field
+
+	contextExtractors []ContextExtractor
+
+	exitFunc       func(code int)
+	panicFunc      func(msg string)
+	noPanicAndExit bool
+	flushTimeout   time.Duration
 }
 
 func (l *Logger) clone() *Logger {
 	lg := &Logger{
 		errOut:          l.errOut,
 		stackTraceLevel: l.stackTraceLevel,
+		stackExtractors: l.stackExtractors,
+		captureStack:    l.captureStack,
+		errorDetails:    l.errorDetails,
+		name:            l.name,
+		hooks:           l.hooks,
+		redactor:        l.redactor,
+		levels:          l.levels,
+		defaultLevels:   l.defaultLevels,
 
 		clock:  l.clock,
 		lock:   l.lock,
 		fields: map[string]Field{},
+
+		contextExtractors: l.contextExtractors,
+
+		exitFunc:       l.exitFunc,
+		panicFunc:      l.panicFunc,
+		noPanicAndExit: l.noPanicAndExit,
+		flushTimeout:   l.flushTimeout,
 	}
 	for k := range l.fields {
 		lg.fields[k] = l.fields[k]
 	}
-	for i := range l.handlers {
-		lg.handlers = append(lg.handlers, l.handlers[i].clone())
-	}
+	lg.handlers = l.handlers
 	return lg
 }
 
@@ -176,6 +389,8 @@ func (l *Logger) Errorf(msg string, a ...interface{}) {
 	l.log(ErrorLevel, msg, a, nil)
 }
 
+// Panic logs the message at PanicLevel, flushes the handlers and panics with
+// the message.
 func (l *Logger) Panic(msg string, fields ...Field) {
 	l.log(PanicLevel, msg, nil, fields)
 }
@@ -184,6 +399,8 @@ func (l *Logger) Panicf(msg string, a ...interface{}) {
 	l.log(PanicLevel, msg, a, nil)
 }
 
+// Fatal logs the message at FatalLevel, closes the handlers and exits the
+// process with status 1.
 func (l *Logger) Fatal(msg string, fields ...Field) {
 	l.log(FatalLevel, msg, nil, fields)
 }
@@ -199,59 +416,184 @@ func (l *Logger) WithFields(fields ...Field) *Entry {
 	return e
 }
 
+// Close flushes pending entries for up to the flush timeout and closes all
+// handlers.
 func (l *Logger) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.flushTimeout)
+	defer cancel()
+
+	// deliver queued entries without blocking the logging goroutines
+	if err := l.Sync(ctx); err != nil && l.errOut != nil {
+		fmt.Fprintf(l.errOut, "sync error: %v\n", err)
+	}
+
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
 	for i := range l.handlers {
-		l.handlers[i].close()
+		var err error
+		if c, ok := l.handlers[i].(contextCloser); ok {
+			err = c.closeContext(ctx)
+		} else {
+			err = l.handlers[i].Close()
+		}
+		if err != nil && l.errOut != nil {
+			fmt.Fprintf(l.errOut, "%T close error: %v\n", l.handlers[i], err)
+		}
 	}
 }
 
-func (l *Logger) log(lvl Level, template string, fmtArgs []interface{}, fields []Field) {
-	var e *Entry
+// Sync waits until entries queued by asynchronous handlers are delivered or
+// ctx is done.
+func (l *Logger) Sync(ctx context.Context) error {
 	for i := range l.handlers {
-		if l.handlers[i].isLevelEnabled(lvl) {
-			if e == nil {
-				e = l.newEntry()
-				defer func() {
-					entryPool.Put(e)
-				}()
-
-				e.level = lvl
-				e.message = sprintf(template, fmtArgs)
-				e.logger = l
-				e.logCaller(defaultFramesToSkip)
-				e.stack = nil
-
-				for i := range fields {
-					if fields[i].tp == errorType && fields[i].err != nil {
-						if l.shouldPrintTrace(lvl) {
-							stackTracer := getStackTracer(fields[i].err)
-							if stackTracer != nil {
-								e.stack = stackTracer.StackTrace()
-							}
-						}
-					}
-				}
+		if s, ok := l.handlers[i].(Syncer); ok {
+			if err := s.Sync(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stats holds delivery counters of asynchronous handlers.
+type Stats struct {
+	// Dropped is the number of entries discarded because a queue was full.
+	Dropped uint64
+	// Failed is the number of entries which could not be written.
+	Failed uint64
+}
+
+func (l *Logger) Stats() Stats {
+	var stats Stats
+	for i := range l.handlers {
+		if s, ok := l.handlers[i].(statsProvider); ok {
+			hs := s.stats()
+			stats.Dropped += hs.Dropped
+			stats.Failed += hs.Failed
+		}
+	}
+	return stats
+}
+
+func (l *Logger) log(lvl Level, template string, fmtArgs []interface{}, fields []Field) {
+	enabled := l.isLevelEnabled(lvl)
+	if !enabled && !l.terminates(lvl) {
+		return
+	}
+
+	msg := sprintf(template, fmtArgs)
+	if enabled {
+		e := l.newEntry()
+		defer entryPool.Put(e)
+
+		e.level = lvl
+		e.message = msg
+		e.logCaller(defaultFramesToSkip)
+
+		l.write(e, fields)
+	}
+
+	l.terminate(lvl, msg)
+}
+
+func (l *Logger) terminates(lvl Level) bool {
+	return !l.noPanicAndExit && (lvl == PanicLevel || lvl == FatalLevel)
+}
+
+// terminate panics after PanicLevel entries and exits after FatalLevel
+// entries. Pending entries are delivered first for up to the flush timeout.
+func (l *Logger) terminate(lvl Level, msg string) {
+	if !l.terminates(lvl) {
+		return
+	}
+	switch lvl {
+	case PanicLevel:
+		ctx, cancel := context.WithTimeout(context.Background(), l.flushTimeout)
+		defer cancel()
+		if err := l.Sync(ctx); err != nil && l.errOut != nil {
+			fmt.Fprintf(l.errOut, "sync error: %v\n", err)
+		}
+		l.panicFunc(msg)
+	case FatalLevel:
+		l.Close()
+		l.exitFunc(1)
+	}
+}
+
+func defaultPanicFunc(msg string) {
+	panic(msg)
+}
 
-				for i := range l.fields {
-					fields = append(fields, l.fields[i])
+// write redacts the entry, runs the hooks and hands it to every handler
+// accepting its level.
+func (l *Logger) write(e *Entry, fields []Field) {
+	e.logger = l
+	e.name = l.name
+	e.stacks = nil
+
+	if l.shouldPrintTrace(e.level) {
+		for i := range fields {
+			if fields[i].tp == errorType && fields[i].err != nil {
+				if stacks := errorStacks(fields[i].err, l.stackExtractors); len(stacks) > 0 {
+					e.stacks = stacks
+				} else if l.captureStack && e.stacks == nil {
+					// skip write, log and the logging method
+					e.stacks = []Stack{callersStack(3)}
 				}
+			}
+		}
+	}
 
-				l.lock.Lock()
-				defer l.lock.Unlock()
+	for i := range l.fields {
+		fields = append(fields, l.fields[i])
+	}
+	if l.errorDetails != 0 {
+		for i, n := 0, len(fields); i < n; i++ {
+			if fields[i].tp == errorType && fields[i].err != nil {
+				fields = append(fields, errorDetailFields(fields[i], l.errorDetails)...)
 			}
+		}
+	}
+	e.fields = marshalFields(fields)
 
-			if err := l.handlers[i].write(e, fields); err != nil {
-				if l.errOut != nil {
-					fmt.Fprintf(l.errOut, "%v write error: %v\n", e.time, err)
-				}
+	// hooks only see redacted entries
+	if l.redactor != nil {
+		l.redactor.redactEntry(e)
+	}
+	// hooks run before taking the lock, so they can log themselves
+	if !l.runHooks(e) {
+		return
+	}
+	// objects added by the hooks
+	e.fields = marshalFields(e.fields)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	named, hasNamed := l.levels.Level(l.name)
+	for i := range l.handlers {
+		if !l.handlerEnabled(l.handlers[i], e.level, named, hasNamed) {
+			continue
+		}
+		if err := l.handlers[i].Write(Record{e: e}); err != nil {
+			if l.errOut != nil {
+				fmt.Fprintf(l.errOut, "%v write error: %v\n", e.time, err)
 			}
 		}
 	}
 }
 
+func (l *Logger) isLevelEnabled(lvl Level) bool {
+	named, hasNamed := l.levels.Level(l.name)
+	for i := range l.handlers {
+		if l.handlerEnabled(l.handlers[i], lvl, named, hasNamed) {
+			return true
+		}
+	}
+	return false
+}
+
 func (l *Logger) shouldPrintTrace(lvl Level) bool {
 	return l.stackTraceLevel.GetLevel() >= lvl
 }
@@ -287,6 +629,41 @@ func readEnvOrDefaultLevel(key string, defaultLevel Level) Level {
 	return lvl
 }
 
+// readEnvLevels reads the default level and the levels of named loggers from
+// a spec like "info,api.billing=debug". An invalid spec is ignored.
+func readEnvLevels(key string, defaultLevel Level) (Level, *LevelRegistry) {
+	registry := NewLevelRegistry()
+	spec := os.Getenv(key)
+	if spec == "" {
+		return defaultLevel, registry
+	}
+
+	lvl, ok, levels, err := ParseLevels(spec)
+	if err != nil {
+		return defaultLevel, registry
+	}
+	if !ok {
+		lvl = defaultLevel
+	}
+	for prefix, l := range levels {
+		registry.SetLevel(prefix, l)
+	}
+	return lvl, registry
+}
+
+func readEnvOrDefaultFormat(key string, defaultFormat Format) Format {
+	format := os.Getenv(key)
+	if format == "" {
+		return defaultFormat
+	}
+
+	f, err := ParseFormat(format)
+	if err != nil {
+		return defaultFormat
+	}
+	return f
+}
+
 func readEnvOrDefaultUseColors(defaultUseColors bool) bool {
 	useColors := os.Getenv("PINE_COLORS")
 	if useColors == "" {
@@ -333,88 +710,3 @@ func readGraylogExtraFields(prefix string) map[string]Field {
 	}
 	return fields
 }
-
-type handler interface {
-	isLevelEnabled(lvl Level) bool
-	write(ent *Entry, fields []Field) error
-	clone() handler
-	close()
-}
-
-type consoleHandler struct {
-	level   *LevelValue
-	encoder encoder
-	out     io.Writer
-}
-
-func (h *consoleHandler) isLevelEnabled(lvl Level) bool {
-	return h.level.GetLevel() >= lvl
-}
-
-func (h *consoleHandler) write(ent *Entry, fields []Field) error {
-	buf, err := h.encoder.encodeEntry(ent, fields)
-	if err != nil {
-		return err
-	}
-	_, err = h.out.Write(buf)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (h *consoleHandler) clone() handler {
-	return &consoleHandler{
-		level:   h.level,
-		encoder: h.encoder.clone(),
-		out:     h.out,
-	}
-}
-
-func (h *consoleHandler) close() {
-	//noop
-}
-
-type gelfHandler struct {
-	level   *LevelValue
-	encoder encoder
-	out     io.WriteCloser
-	errOut  io.Writer
-	mu      sync.Mutex
-}
-
-func (h *gelfHandler) isLevelEnabled(lvl Level) bool {
-	return h.level.GetLevel() >= lvl
-}
-
-func (h *gelfHandler) write(ent *Entry, fields []Field) error {
-	buf, err := h.encoder.encodeEntry(ent, fields)
-	if err != nil {
-		return err
-	}
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	_, err = h.out.Write(buf)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (h *gelfHandler) clone() handler {
-	return &gelfHandler{
-		level:   h.level,
-		encoder: h.encoder.clone(),
-		out:     h.out,
-		errOut:  h.errOut,
-	}
-}
-
-func (h *gelfHandler) close() {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	err := h.out.Close()
-	if err != nil {
-		fmt.Fprintf(h.errOut, "gelf close error: %v\n", err)
-	}
-}
@@ -323,8 +323,8 @@ func TestLogger_Graylog(t *testing.T) {
 			},
 			wantConsoleLog: "2022-08-10T21:29:59.123Z INF hello A=B\n2022-08-10T21:29:59.123Z INF hello2 A1=B1\n",
 			wantGelfLog: []string{
-				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
-				`{"version":"1.1","host":"kronos.local","short_message":"hello2","timestamp":1660166999,"level":6,"_A1":"B1","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
+				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999.123,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
+				`{"version":"1.1","host":"kronos.local","short_message":"hello2","timestamp":1660166999.123,"level":6,"_A1":"B1","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
 			},
 		},
 		{
@@ -336,8 +336,8 @@ func TestLogger_Graylog(t *testing.T) {
 			},
 			wantConsoleLog: "2022-08-10T21:29:59.123Z INF hello A=B\n",
 			wantGelfLog: []string{
-				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
-				`{"version":"1.1","host":"kronos.local","short_message":"hello2","timestamp":1660166999,"level":7,"_A1":"B1","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
+				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999.123,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
+				`{"version":"1.1","host":"kronos.local","short_message":"hello2","timestamp":1660166999.123,"level":7,"_A1":"B1","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
 			},
 		},
 		{
@@ -349,7 +349,7 @@ func TestLogger_Graylog(t *testing.T) {
 			},
 			wantConsoleLog: "2022-08-10T21:29:59.123Z INF hello A=B\n",
 			wantGelfLog: []string{
-				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2,"_pod":"api-7776bb867b-mk4m6","_source":"api-service"}`,
+				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999.123,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2,"_pod":"api-7776bb867b-mk4m6","_source":"api-service"}`,
 			},
 		},
 		{
@@ -361,7 +361,7 @@ func TestLogger_Graylog(t *testing.T) {
 			},
 			wantConsoleLog: "2022-08-10T21:29:59.123Z INF hello A=B\n",
 			wantGelfLog: []string{
-				`{"version":"1.1","host":"api-service","short_message":"hello","timestamp":1660166999,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
+				`{"version":"1.1","host":"api-service","short_message":"hello","timestamp":1660166999.123,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
 			},
 		},
 		{
@@ -373,7 +373,7 @@ func TestLogger_Graylog(t *testing.T) {
 			},
 			wantConsoleLog: "2022-08-10T21:29:59.123Z INF hello A=B host=customhost\n",
 			wantGelfLog: []string{
-				`{"version":"1.1","host":"customhost","short_message":"hello","timestamp":1660166999,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
+				`{"version":"1.1","host":"customhost","short_message":"hello","timestamp":1660166999.123,"level":6,"_A":"B","_caller":"logger_test.go:2","_file":"logger_test.go","_line":2}`,
 			},
 		},
 		{
@@ -385,7 +385,7 @@ func TestLogger_Graylog(t *testing.T) {
 			wantConsoleLog: `2022-08-10T21:29:59.123Z ERR hello A=B error="test error" stack="TestLogger_Graylog.func7() at logger_test.go:383 <- TestLogger_Graylog.func9() at logger_test.go:412 <- tRunner() at testing.go:1108 <- goexit() at asm_amd64.s:1374"
 `,
 			wantGelfLog: []string{
-				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999,"level":3,"_A":"B","_caller":"logger_test.go:2","_error":"test error","_file":"logger_test.go","_line":2,"_stack":"\ngithub.com/go-pckg/pine.TestLogger_Graylog.func7\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:383\ngithub.com/go-pckg/pine.TestLogger_Graylog.func9\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:412\ntesting.tRunner\n\t/usr/local/go/src/testing/testing.go:1108\nruntime.goexit\n\t/usr/local/go/src/runtime/asm_amd64.s:1374"}`,
+				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999.123,"level":3,"_A":"B","_caller":"logger_test.go:2","_error":"test error","_file":"logger_test.go","_line":2,"_stack":"\ngithub.com/go-pckg/pine.TestLogger_Graylog.func7\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:383\ngithub.com/go-pckg/pine.TestLogger_Graylog.func9\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:412\ntesting.tRunner\n\t/usr/local/go/src/testing/testing.go:1108\nruntime.goexit\n\t/usr/local/go/src/runtime/asm_amd64.s:1374"}`,
 			},
 		},
 		{
@@ -397,7 +397,7 @@ func TestLogger_Graylog(t *testing.T) {
 			wantConsoleLog: `2022-08-10T21:29:59.123Z ERR hello error="test error" stack=custom stack="TestLogger_Graylog.func8() at logger_test.go:395 <- TestLogger_Graylog.func9() at logger_test.go:412 <- tRunner() at testing.go:1108 <- goexit() at asm_amd64.s:1374"
 `,
 			wantGelfLog: []string{
-				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999,"level":3,"_caller":"logger_test.go:2","_error":"test error","_file":"logger_test.go","_line":2,"_stack":"\ngithub.com/go-pckg/pine.TestLogger_Graylog.func8\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:395\ngithub.com/go-pckg/pine.TestLogger_Graylog.func9\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:412\ntesting.tRunner\n\t/usr/local/go/src/testing/testing.go:1108\nruntime.goexit\n\t/usr/local/go/src/runtime/asm_amd64.s:1374"}`,
+				`{"version":"1.1","host":"kronos.local","short_message":"hello","timestamp":1660166999.123,"level":3,"_caller":"logger_test.go:2","_error":"test error","_file":"logger_test.go","_line":2,"_stack":"\ngithub.com/go-pckg/pine.TestLogger_Graylog.func8\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:395\ngithub.com/go-pckg/pine.TestLogger_Graylog.func9\n\t/Users/glebteterin/projects/study/go/pine/logger_test.go:412\ntesting.tRunner\n\t/usr/local/go/src/testing/testing.go:1108\nruntime.goexit\n\t/usr/local/go/src/runtime/asm_amd64.s:1374"}`,
 			},
 		},
 	}
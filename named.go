@@ -0,0 +1,147 @@
+package pine
+
+import (
+	"strings"
+	"sync"
+)
+
+// LevelRegistry holds levels of named loggers by name prefix. A level set for
+// "api" applies to the loggers "api", "api.billing" and so on; the longest
+// matching prefix wins. A matching level is the threshold of that logger: it
+// replaces the default level of the console and of the outputs without a
+// level of their own, so a single component can be made more or less
+// verbose, while outputs with their own level, like GraylogLevel, and
+// handlers added with HandlerWithLevel keep filtering by it.
+type LevelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]Level
+}
+
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{levels: map[string]Level{}}
+}
+
+// SetLevel sets the level of the loggers named prefix or below it.
+func (r *LevelRegistry) SetLevel(prefix string, lvl Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.levels[prefix] = lvl
+}
+
+// Unset removes the level set for prefix.
+func (r *LevelRegistry) Unset(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.levels, prefix)
+}
+
+// Level returns the level for the logger name using the longest matching
+// prefix. It reports false when no prefix matches.
+func (r *LevelRegistry) Level(name string) (Level, bool) {
+	if r == nil || name == "" {
+		return DisabledLevel, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.levels) == 0 {
+		return DisabledLevel, false
+	}
+	for {
+		if lvl, ok := r.levels[name]; ok {
+			return lvl, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return DisabledLevel, false
+		}
+		name = name[:i]
+	}
+}
+
+// Levels returns a copy of the levels by prefix.
+func (r *LevelRegistry) Levels() map[string]Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	levels := make(map[string]Level, len(r.levels))
+	for prefix, lvl := range r.levels {
+		levels[prefix] = lvl
+	}
+	return levels
+}
+
+// ParseLevels parses a spec like "info,api.billing=debug,db=warn". The item
+// without a name is the default level; it reports false when there is none.
+func ParseLevels(spec string) (Level, bool, map[string]Level, error) {
+	var def Level
+	var hasDef bool
+	levels := map[string]Level{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		eq := strings.IndexByte(item, '=')
+		if eq < 0 {
+			lvl, err := ParseLevel(item)
+			if err != nil {
+				return def, false, nil, err
+			}
+			def, hasDef = lvl, true
+			continue
+		}
+		lvl, err := ParseLevel(strings.TrimSpace(item[eq+1:]))
+		if err != nil {
+			return def, false, nil, err
+		}
+		levels[strings.TrimSpace(item[:eq])] = lvl
+	}
+	return def, hasDef, levels, nil
+}
+
+// Named returns a logger with name appended to the name of l, separated by a
+// dot. The name is part of the output and selects levels from the registry.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	lg := l.clone()
+	if l.name == "" {
+		lg.name = name
+	} else {
+		lg.name = l.name + "." + name
+	}
+	return lg
+}
+
+// Name returns the dotted name of the logger.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// LevelRegistry returns the registry of levels by logger name.
+func (l *Logger) LevelRegistry() *LevelRegistry {
+	return l.levels
+}
+
+type leveler interface {
+	levelValue() *LevelValue
+}
+
+// handlerEnabled reports whether h accepts entries of lvl. A level from the
+// registry replaces the default level of outputs and is combined with the
+// levels of other handlers.
+func (l *Logger) handlerEnabled(h Handler, lvl Level, named Level, hasNamed bool) bool {
+	if !hasNamed {
+		return h.Enabled(lvl)
+	}
+	if named < lvl {
+		return false
+	}
+	if lh, ok := h.(leveler); ok {
+		if _, ok := l.defaultLevels[lh.levelValue()]; ok {
+			return true
+		}
+	}
+	return h.Enabled(lvl)
+}
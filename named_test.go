@@ -0,0 +1,147 @@
+package pine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Named(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()), WithLevel(InfoLevel))
+
+	billing := lgr.Named("api").Named("billing")
+	assert.Equal(t, "api.billing", billing.Name())
+	assert.Equal(t, "", lgr.Name())
+
+	billing.With(Int("i", 1)).Named("invoices").Info("hello")
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF [api.billing.invoices] hello i=1\n", buf.String())
+
+	buf.Reset()
+	lgr = New(Output(buf), WithClock(newTestClock()), WithFormat(JSONFormat))
+	lgr.Named("db").Info("hello")
+	assert.Equal(t, `{"time":"2022-08-10T21:29:59.123Z","level":"info","logger":"db","message":"hello"}`+"\n", buf.String())
+}
+
+func TestLogger_NamedLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	registry := NewLevelRegistry()
+	registry.SetLevel("api", WarnLevel)
+	registry.SetLevel("api.billing", DebugLevel)
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()), WithLevel(InfoLevel), WithLevelRegistry(registry))
+
+	lgr.Debug("root")
+	lgr.Named("api").Info("api")
+	lgr.Named("api").Named("billing").Named("invoices").Debug("invoices")
+	lgr.Named("apis").Info("apis")
+	assert.Equal(t, ""+
+		"2022-08-10T21:29:59.123Z DBG [api.billing.invoices] invoices\n"+
+		"2022-08-10T21:29:59.123Z INF [apis] apis\n", buf.String())
+
+	buf.Reset()
+	registry.Unset("api.billing")
+	lgr.Named("api").Named("billing").Info("billing")
+	registry.SetLevel("api", TraceLevel)
+	lgr.Named("api").Trace("api")
+	assert.Equal(t, "2022-08-10T21:29:59.123Z TRC [api] api\n", buf.String())
+}
+
+func recordedMessages(h *recordingHandler) []string {
+	var messages []string
+	for _, e := range h.entries {
+		messages = append(messages, e.message)
+	}
+	return messages
+}
+
+func TestLogger_NamedLevelsKeepOutputLevels(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PINE_LEVEL", "info,api.billing=debug,db=warn")
+	defer os.Clearenv()
+
+	buf := &bytes.Buffer{}
+	strict := &recordingHandler{}
+	open := &recordingHandler{}
+	path := filepath.Join(t.TempDir(), "app.log")
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()),
+		WithHandler(HandlerWithLevel(strict, NewLevelValue(ErrorLevel))),
+		WithHandler(HandlerWithLevel(open, NewLevelValue(TraceLevel))),
+		File(path, RotateOptions{}), FileLevel(ErrorLevel))
+
+	billing := lgr.Named("api").Named("billing")
+	billing.Debug("debug")
+	billing.Error("error")
+	lgr.Named("db").Info("info")
+	lgr.Close()
+
+	assert.Equal(t, ""+
+		"2022-08-10T21:29:59.123Z DBG [api.billing] debug\n"+
+		"2022-08-10T21:29:59.123Z ERR [api.billing] error\n", buf.String())
+	assert.Equal(t, []string{"error"}, recordedMessages(strict))
+	// the named level is the threshold of handlers with a more verbose level
+	assert.Equal(t, []string{"debug", "error"}, recordedMessages(open))
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-08-10T21:29:59.123Z ERR [api.billing] error\n", string(b))
+}
+
+func TestLogger_NamedLevelsReplaceDefaultOutputLevels(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PINE_LEVEL", "info,api=debug")
+	defer os.Clearenv()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	lgr := New(NoConsole(), WithClock(newTestClock()), File(path, RotateOptions{}))
+	lgr.Named("api").Debug("debug")
+	lgr.Debug("dropped")
+	lgr.Close()
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-08-10T21:29:59.123Z DBG [api] debug\n", string(b))
+}
+
+func TestParseLevels(t *testing.T) {
+	def, ok, levels, err := ParseLevels("info, api.billing=debug,db=warn")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, InfoLevel, def)
+	assert.Equal(t, map[string]Level{"api.billing": DebugLevel, "db": WarnLevel}, levels)
+
+	_, ok, levels, err = ParseLevels("db=error")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, map[string]Level{"db": ErrorLevel}, levels)
+
+	_, _, _, err = ParseLevels("info,db=loud")
+	assert.Error(t, err)
+}
+
+func TestEnvNamedLevels(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PINE_LEVEL", "info,api.billing=debug,db=warn")
+	defer os.Clearenv()
+
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()))
+	assert.Equal(t, InfoLevel, lgr.handlers[0].(*consoleHandler).level.GetLevel())
+	assert.Equal(t, map[string]Level{"api.billing": DebugLevel, "db": WarnLevel}, lgr.LevelRegistry().Levels())
+
+	lgr.Debug("root")
+	lgr.Named("api").Named("billing").Debug("billing")
+	lgr.Named("db").Info("db")
+	assert.Equal(t, "2022-08-10T21:29:59.123Z DBG [api.billing] billing\n", buf.String())
+}
+
+func TestGelfEncoder_LoggerName(t *testing.T) {
+	ent := &Entry{name: "api.billing", level: InfoLevel, time: testDate, message: "hello"}
+	b, err := newGelfEncoder(nil, GelfFieldOptions{}, defaultGelfMessageOptions).encodeEntry(ent, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"_logger":"api.billing"`)
+}
@@ -0,0 +1,387 @@
+package pine
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ObjectMarshaler is implemented by types which log themselves as a set of
+// keys and values, so encoders can render them without stringification.
+type ObjectMarshaler interface {
+	MarshalLogObject(enc ObjectEncoder) error
+}
+
+// ArrayMarshaler is implemented by types which log themselves as a list.
+type ArrayMarshaler interface {
+	MarshalLogArray(enc ArrayEncoder) error
+}
+
+// ObjectEncoder receives the keys and values of an ObjectMarshaler.
+type ObjectEncoder interface {
+	AddString(key, value string)
+	AddInt(key string, value int)
+	AddInt64(key string, value int64)
+	AddFloat64(key string, value float64)
+	AddBool(key string, value bool)
+	AddTime(key string, value time.Time)
+	AddObject(key string, obj ObjectMarshaler) error
+	AddArray(key string, arr ArrayMarshaler) error
+}
+
+// ArrayEncoder receives the elements of an ArrayMarshaler.
+type ArrayEncoder interface {
+	AppendString(value string)
+	AppendInt(value int)
+	AppendInt64(value int64)
+	AppendFloat64(value float64)
+	AppendBool(value bool)
+	AppendTime(value time.Time)
+	AppendObject(obj ObjectMarshaler) error
+	AppendArray(arr ArrayMarshaler) error
+}
+
+func Object(key string, val ObjectMarshaler) Field {
+	return Field{tp: objectType, key: key, value: val}
+}
+
+func Array(key string, val ArrayMarshaler) Field {
+	return Field{tp: arrayType, key: key, value: val}
+}
+
+func Strings(key string, val []string) Field {
+	return Array(key, stringArray(val))
+}
+
+func Ints(key string, val []int) Field {
+	return Array(key, intArray(val))
+}
+
+// Any returns a field of the type matching val. Values of other types are
+// logged like Interface. Nil pointers to marshalers are logged as null.
+func Any(key string, val interface{}) Field {
+	switch v := val.(type) {
+	case ObjectMarshaler:
+		return Object(key, v)
+	case ArrayMarshaler:
+		return Array(key, v)
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int8:
+		return Int8(key, v)
+	case int16:
+		return Int16(key, v)
+	case int32:
+		return Int32(key, v)
+	case int64:
+		return Int64(key, v)
+	case float32:
+		return Float32(key, v)
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return Field{tp: errorType, key: key, err: v}
+	case []string:
+		return Strings(key, v)
+	case []int:
+		return Ints(key, v)
+	default:
+		return Interface(key, v)
+	}
+}
+
+type stringArray []string
+
+func (a stringArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, s := range a {
+		enc.AppendString(s)
+	}
+	return nil
+}
+
+type intArray []int
+
+func (a intArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, i := range a {
+		enc.AppendInt(i)
+	}
+	return nil
+}
+
+// objectValue and arrayValue hold a marshaled object or array. Their leaves
+// are string, int64, float64, bool or time.Time.
+type objectValue []objectEntry
+
+type objectEntry struct {
+	key   string
+	value interface{}
+}
+
+type arrayValue []interface{}
+
+func (o *objectValue) AddString(key, value string)          { o.add(key, value) }
+func (o *objectValue) AddInt(key string, value int)         { o.add(key, int64(value)) }
+func (o *objectValue) AddInt64(key string, value int64)     { o.add(key, value) }
+func (o *objectValue) AddFloat64(key string, value float64) { o.add(key, value) }
+func (o *objectValue) AddBool(key string, value bool)       { o.add(key, value) }
+func (o *objectValue) AddTime(key string, value time.Time)  { o.add(key, value) }
+
+func (o *objectValue) AddObject(key string, obj ObjectMarshaler) error {
+	v, err := marshalObject(obj)
+	o.add(key, v)
+	return err
+}
+
+func (o *objectValue) AddArray(key string, arr ArrayMarshaler) error {
+	v, err := marshalArray(arr)
+	o.add(key, v)
+	return err
+}
+
+func (o *objectValue) add(key string, value interface{}) {
+	*o = append(*o, objectEntry{key: key, value: value})
+}
+
+func (a *arrayValue) AppendString(value string)   { *a = append(*a, value) }
+func (a *arrayValue) AppendInt(value int)         { *a = append(*a, int64(value)) }
+func (a *arrayValue) AppendInt64(value int64)     { *a = append(*a, value) }
+func (a *arrayValue) AppendFloat64(value float64) { *a = append(*a, value) }
+func (a *arrayValue) AppendBool(value bool)       { *a = append(*a, value) }
+func (a *arrayValue) AppendTime(value time.Time)  { *a = append(*a, value) }
+
+func (a *arrayValue) AppendObject(obj ObjectMarshaler) error {
+	v, err := marshalObject(obj)
+	*a = append(*a, v)
+	return err
+}
+
+func (a *arrayValue) AppendArray(arr ArrayMarshaler) error {
+	v, err := marshalArray(arr)
+	*a = append(*a, v)
+	return err
+}
+
+func marshalObject(obj ObjectMarshaler) (v interface{}, err error) {
+	if obj == nil {
+		return objectValue{}, nil
+	}
+	if isNilPointer(obj) {
+		// MarshalLogObject would be called on nil
+		return nil, nil
+	}
+	defer recoverMarshal(obj, &err)
+	o := objectValue{}
+	err = obj.MarshalLogObject(&o)
+	return o, err
+}
+
+func marshalArray(arr ArrayMarshaler) (v interface{}, err error) {
+	if arr == nil {
+		return arrayValue{}, nil
+	}
+	if isNilPointer(arr) {
+		return nil, nil
+	}
+	defer recoverMarshal(arr, &err)
+	a := arrayValue{}
+	err = arr.MarshalLogArray(&a)
+	return a, err
+}
+
+// recoverMarshal turns a panicking marshaler into an error, which is
+// reported to the error output.
+func recoverMarshal(m interface{}, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%T marshal panic: %v", m, r)
+	}
+}
+
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// marshaled is the value of an object or array field marshaled by
+// marshalFields. src is the value returned by Field.Value.
+type marshaled struct {
+	src  interface{}
+	tree interface{}
+}
+
+// marshalFields marshals the object and array fields once per entry, so the
+// handlers and the redaction share the result. A field failing to marshal is
+// replaced by <key>Error holding the error.
+func marshalFields(fields []Field) []Field {
+	var out []Field
+	for i := range fields {
+		f := fields[i]
+		if f.tp != objectType && f.tp != arrayType {
+			continue
+		}
+		if _, ok := f.value.(marshaled); ok {
+			continue
+		}
+		if out == nil {
+			// copy on write, the fields may belong to the caller
+			out = make([]Field, len(fields))
+			copy(out, fields)
+		}
+		v, err := marshalField(f)
+		if err != nil {
+			out[i] = String(f.key+"Error", err.Error())
+			continue
+		}
+		out[i].value = marshaled{src: f.value, tree: v}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// marshalField marshals an object or array field.
+func marshalField(field Field) (interface{}, error) {
+	if m, ok := field.value.(marshaled); ok {
+		return m.tree, nil
+	}
+	if field.tp == objectType {
+		obj, _ := field.value.(ObjectMarshaler)
+		return marshalObject(obj)
+	}
+	arr, _ := field.value.(ArrayMarshaler)
+	return marshalArray(arr)
+}
+
+// flattenValue calls fn for every leaf of v with the keys joined by sep,
+// e.g. req.user.id or tags.0.
+func flattenValue(key, sep string, v interface{}, fn func(key string, leaf interface{})) {
+	switch t := v.(type) {
+	case objectValue:
+		for _, e := range t {
+			flattenValue(key+sep+e.key, sep, e.value, fn)
+		}
+	case arrayValue:
+		for i, e := range t {
+			flattenValue(key+sep+strconv.Itoa(i), sep, e, fn)
+		}
+	default:
+		fn(key, v)
+	}
+}
+
+func leafString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'E', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
+
+func appendJSONValue(b *bytes.Buffer, v interface{}) {
+	switch t := v.(type) {
+	case objectValue:
+		b.WriteByte('{')
+		for i, e := range t {
+			appendJSONKey(b, e.key, i == 0)
+			appendJSONValue(b, e.value)
+		}
+		b.WriteByte('}')
+	case arrayValue:
+		b.WriteByte('[')
+		for i, e := range t {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			appendJSONValue(b, e)
+		}
+		b.WriteByte(']')
+	case string:
+		appendJSONString(b, t)
+	case int64:
+		b.WriteString(strconv.FormatInt(t, 10))
+	case float64:
+		appendJSONFloat(b, t, 64)
+	case bool:
+		b.WriteString(strconv.FormatBool(t))
+	case time.Time:
+		appendJSONString(b, t.Format(time.RFC3339Nano))
+	default:
+		b.WriteString("null")
+	}
+}
+
+// MarshalLogObject replays the marshaled object, e.g. one whose values were
+// redacted.
+func (o objectValue) MarshalLogObject(enc ObjectEncoder) error {
+	for _, e := range o {
+		switch t := e.value.(type) {
+		case objectValue:
+			_ = enc.AddObject(e.key, t)
+		case arrayValue:
+			_ = enc.AddArray(e.key, t)
+		case string:
+			enc.AddString(e.key, t)
+		case int64:
+			enc.AddInt64(e.key, t)
+		case float64:
+			enc.AddFloat64(e.key, t)
+		case bool:
+			enc.AddBool(e.key, t)
+		case time.Time:
+			enc.AddTime(e.key, t)
+		}
+	}
+	return nil
+}
+
+// MarshalLogArray replays the marshaled array.
+func (a arrayValue) MarshalLogArray(enc ArrayEncoder) error {
+	for _, e := range a {
+		switch t := e.(type) {
+		case objectValue:
+			_ = enc.AppendObject(t)
+		case arrayValue:
+			_ = enc.AppendArray(t)
+		case string:
+			enc.AppendString(t)
+		case int64:
+			enc.AppendInt64(t)
+		case float64:
+			enc.AppendFloat64(t)
+		case bool:
+			enc.AppendBool(t)
+		case time.Time:
+			enc.AppendTime(t)
+		}
+	}
+	return nil
+}
+
+func (o objectValue) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	appendJSONValue(b, o)
+	return b.Bytes(), nil
+}
+
+func (a arrayValue) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	appendJSONValue(b, a)
+	return b.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUser struct {
+	ID    int
+	Roles []string
+}
+
+func (u testUser) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt("id", u.ID)
+	return enc.AddArray("roles", stringArray(u.Roles))
+}
+
+type testRequest struct {
+	Method string
+	User   testUser
+}
+
+func (r testRequest) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("method", r.Method)
+	enc.AddBool("auth", true)
+	return enc.AddObject("user", r.User)
+}
+
+var testReq = testRequest{Method: "GET", User: testUser{ID: 7, Roles: []string{"admin", "dev"}}}
+
+func TestObject_Console(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()))
+
+	lgr.Info("hello", Object("req", testReq), Ints("ids", []int{1, 2}))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF hello ids.0=1 ids.1=2 req.method=GET req.auth=true req.user.id=7 "+
+		"req.user.roles.0=admin req.user.roles.1=dev\n", buf.String())
+}
+
+func TestObject_JSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(Output(buf), WithClock(newTestClock()), WithFormat(JSONFormat))
+
+	lgr.Info("hello", Object("req", testReq), Strings("tags", []string{"a"}), Array("empty", nil))
+	assert.Equal(t, `{"time":"2022-08-10T21:29:59.123Z","level":"info","message":"hello","empty":[],`+
+		`"req":{"method":"GET","auth":true,"user":{"id":7,"roles":["admin","dev"]}},"tags":["a"]}`+"\n", buf.String())
+}
+
+func TestObject_Gelf(t *testing.T) {
+	ent := &Entry{level: InfoLevel, time: testDate, message: "hello"}
+	b, err := newGelfEncoder(nil, GelfFieldOptions{}, defaultGelfMessageOptions).encodeEntry(ent, []Field{Object("req", testReq)})
+	require.NoError(t, err)
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(bytes.TrimRight(b, "\n\x00"), &msg))
+	assert.Equal(t, "GET", msg["_req_method"])
+	assert.Equal(t, float64(7), msg["_req_user_id"])
+	assert.Equal(t, "dev", msg["_req_user_roles_1"])
+}
+
+type failingObject struct{}
+
+func (failingObject) MarshalLogObject(enc ObjectEncoder) error {
+	return errors.New("marshal failed")
+}
+
+func TestObject_Error(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), ErrOutput(errBuf), WithClock(newTestClock()))
+
+	lgr.Info("hello", Object("obj", failingObject{}), Int("i", 1))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF hello i=1 objError=\"marshal failed\"\n", buf.String())
+	assert.Equal(t, "", errBuf.String())
+}
+
+type panickingObject struct{}
+
+func (panickingObject) MarshalLogObject(enc ObjectEncoder) error {
+	panic("boom")
+}
+
+func TestObject_NilPointer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	lgr := New(Output(buf), ErrOutput(errBuf), WithClock(newTestClock()), WithFormat(JSONFormat))
+
+	var req *testRequest
+	lgr.Info("hello", Any("any", req), Object("obj", req))
+	assert.Equal(t, `{"time":"2022-08-10T21:29:59.123Z","level":"info","message":"hello","any":null,"obj":null}`+"\n",
+		buf.String())
+	assert.Equal(t, "", errBuf.String())
+}
+
+func TestObject_Panic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), ErrOutput(errBuf), WithClock(newTestClock()))
+
+	lgr.Info("hello", Object("obj", panickingObject{}))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF hello objError=\"pine.panickingObject marshal panic: boom\"\n",
+		buf.String())
+	assert.Equal(t, "", errBuf.String())
+}
+
+type countingObject struct {
+	calls *int
+}
+
+func (o countingObject) MarshalLogObject(enc ObjectEncoder) error {
+	*o.calls++
+	enc.AddString("token", "secret")
+	return enc.AddObject("user", testUser{ID: 7, Roles: []string{"bob@example.com"}})
+}
+
+func TestObject_MarshalOnce(t *testing.T) {
+	console := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	audit := &recordingHandler{}
+	calls := 0
+	obj := countingObject{calls: &calls}
+	lgr := New(NoColors(), Output(console), WithClock(newTestClock()), WithHandler(audit),
+		WithHandler(&consoleHandler{level: NewLevelValue(InfoLevel), encoder: newJSONEncoder(encoderConfig{}), out: file}),
+		Redact(RedactOptions{Keys: []string{"token"}, Detectors: []Detector{EmailDetector()}}))
+
+	lgr.Info("hello", Object("obj", obj))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "2022-08-10T21:29:59.123Z INF hello obj.token=\"[REDACTED]\" obj.user.id=7 obj.user.roles.0=\"[REDACTED]\"\n",
+		console.String())
+	assert.Contains(t, file.String(), `"obj":{"token":"[REDACTED]","user":{"id":7,"roles":["[REDACTED]"]}}`)
+	// the redacted value replaces the source of the field
+	assert.IsType(t, objectValue{}, audit.entries[0].fields["obj"])
+}
+
+func TestAny(t *testing.T) {
+	assert.Equal(t, String("k", "v"), Any("k", "v"))
+	assert.Equal(t, Int("k", 1), Any("k", 1))
+	assert.Equal(t, Bool("k", true), Any("k", true))
+	assert.Equal(t, Strings("k", []string{"a"}), Any("k", []string{"a"}))
+	assert.Equal(t, Object("k", testReq), Any("k", testReq))
+	assert.Equal(t, Interface("k", struct{}{}), Any("k", struct{}{}))
+
+	f := Any("cause", errors.New("boom"))
+	assert.Equal(t, "cause", f.Key())
+	assert.EqualError(t, f.Value().(error), "boom")
+}
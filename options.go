@@ -1,9 +1,17 @@
 package pine
 
 import (
+	"crypto/tls"
 	"io"
+	"time"
+
+	"github.com/go-pckg/pine/file"
+	"github.com/go-pckg/pine/gelf"
 )
 
+// RotateOptions configures rotation of the file output.
+type RotateOptions = file.Options
+
 type Option interface {
 	apply(*config)
 }
@@ -16,7 +24,7 @@ func (f optionFunc) apply(log *config) {
 
 func WithLevel(lvl Level) Option {
 	return optionFunc(func(log *config) {
-		log.consoleConfig.level = NewLevelValue(lvl)
+		log.consoleConfig.level = NewNamedLevelValue("console", lvl)
 	})
 }
 
@@ -26,6 +34,25 @@ func WithLevelValue(lvl *LevelValue) Option {
 	})
 }
 
+func WithFormat(format Format) Option {
+	return optionFunc(func(c *config) {
+		c.consoleConfig.format = format
+	})
+}
+
+func WithJSONKeys(keys JSONKeys) Option {
+	return optionFunc(func(c *config) {
+		c.consoleConfig.encoderConfig.JSONKeys = keys
+	})
+}
+
+// Sampling limits repeated entries written to the console.
+func Sampling(opts SamplingOptions) Option {
+	return optionFunc(func(c *config) {
+		c.consoleConfig.sampling = &opts
+	})
+}
+
 func Colored(useColors bool) Option {
 	return optionFunc(func(log *config) {
 		log.consoleConfig.encoderConfig.UseColors = useColors
@@ -50,6 +77,30 @@ func WithStackTraceLevel(lvl Level) Option {
 	})
 }
 
+// WithStackExtractor adds a function extracting stack traces from errors of
+// other libraries. It is tried before the built-in extractors.
+func WithStackExtractor(extractor StackExtractor) Option {
+	return optionFunc(func(c *config) {
+		c.stackExtractors = append([]StackExtractor{extractor}, c.stackExtractors...)
+	})
+}
+
+// CaptureStack logs the stack of the logging goroutine for errors which
+// carry no stack trace.
+func CaptureStack() Option {
+	return optionFunc(func(c *config) {
+		c.captureStack = true
+	})
+}
+
+// WithErrorDetails logs the type, the causes or the attributes of error
+// fields next to their message.
+func WithErrorDetails(details ErrorDetail) Option {
+	return optionFunc(func(c *config) {
+		c.errorDetails = details
+	})
+}
+
 func ForceQuote() Option {
 	return optionFunc(func(c *config) {
 		c.consoleConfig.encoderConfig.ForceQuote = true
@@ -125,6 +176,254 @@ func Graylog(addr string) Option {
 
 func GraylogLevel(lvl Level) Option {
 	return optionFunc(func(c *config) {
-		c.gelfConfig.Level = NewLevelValue(lvl)
+		c.gelfConfig.Level = NewNamedLevelValue("graylog", lvl)
+	})
+}
+
+func GraylogLevelValue(lvl *LevelValue) Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.Level = lvl
+	})
+}
+
+// GraylogTLS enables TLS for the TCP transport.
+func GraylogTLS(cfg *tls.Config) Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.TLSConfig = cfg
+		c.gelfConfig.tlsErr = nil
+	})
+}
+
+// GraylogAsync delivers GELF messages from a bounded queue drained by a
+// background goroutine instead of writing them while logging.
+func GraylogAsync(opts AsyncOptions) Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.Async = &opts
+	})
+}
+
+// GraylogChunkSize sets the maximum datagram size of the UDP transport.
+func GraylogChunkSize(size int) Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.ChunkSize = size
+	})
+}
+
+// GraylogCompression sets the compression of the UDP transport.
+func GraylogCompression(compression gelf.CompressionType) Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.Compression = compression
+	})
+}
+
+// GraylogFieldOptions controls how field values are mapped to GELF
+// additional fields.
+func GraylogFieldOptions(opts GelfFieldOptions) Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.FieldOptions = opts
+	})
+}
+
+// GraylogMessageOptions controls the timestamp precision, short_message and
+// full_message of GELF messages.
+func GraylogMessageOptions(opts GelfMessageOptions) Option {
+	return optionFunc(func(c *config) {
+		if opts.TimestampPrecision == 0 {
+			opts.TimestampPrecision = time.Millisecond
+		}
+		if opts.FullMessage == 0 {
+			opts.FullMessage = FullMessageText
+		}
+		c.gelfConfig.MessageOptions = opts
+	})
+}
+
+// GraylogNoFullMessage sends no full_message, whatever
+// GelfMessageOptions.FullMessage selects.
+func GraylogNoFullMessage() Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.NoFullMessage = true
+	})
+}
+
+// GraylogSampling limits repeated entries sent to Graylog.
+func GraylogSampling(opts SamplingOptions) Option {
+	return optionFunc(func(c *config) {
+		c.gelfConfig.Sampling = &opts
+	})
+}
+
+// WithLevelRegistry sets the registry of levels by logger name, replacing the
+// one read from PINE_LEVEL.
+func WithLevelRegistry(r *LevelRegistry) Option {
+	return optionFunc(func(c *config) {
+		c.levels = r
+	})
+}
+
+// WithContextExtractor registers a function which adds fields derived from
+// the context to entries logged with the *Ctx methods.
+func WithContextExtractor(extractor ContextExtractor) Option {
+	return optionFunc(func(c *config) {
+		c.contextExtractors = append(c.contextExtractors, extractor)
+	})
+}
+
+// File adds an output writing to the file at path, rotated according to opts.
+func File(path string, opts RotateOptions) Option {
+	return optionFunc(func(c *config) {
+		c.fileConfig.Enabled = true
+		c.fileConfig.Path = path
+		c.fileConfig.Rotate = opts
+	})
+}
+
+func FileLevel(lvl Level) Option {
+	return optionFunc(func(c *config) {
+		c.fileConfig.Level = NewNamedLevelValue("file", lvl)
+	})
+}
+
+func FileLevelValue(lvl *LevelValue) Option {
+	return optionFunc(func(c *config) {
+		c.fileConfig.Level = lvl
+	})
+}
+
+func FileFormat(format Format) Option {
+	return optionFunc(func(c *config) {
+		c.fileConfig.Format = format
+	})
+}
+
+// FileSampling limits repeated entries written to the file.
+func FileSampling(opts SamplingOptions) Option {
+	return optionFunc(func(c *config) {
+		c.fileConfig.Sampling = &opts
+	})
+}
+
+// Syslog adds an output sending entries to a syslog daemon at addr:
+// unix:///dev/log, udp://host:514, tcp://host:601 or tls://host:6514. TCP and
+// TLS messages are framed with octet counting. An empty addr uses the local
+// daemon.
+func Syslog(addr string, opts SyslogOptions) Option {
+	return optionFunc(func(c *config) {
+		c.syslogConfig.Enabled = true
+		c.syslogConfig.Addr = addr
+		c.syslogConfig.Options = opts
+	})
+}
+
+func SyslogLevel(lvl Level) Option {
+	return optionFunc(func(c *config) {
+		c.syslogConfig.Level = NewNamedLevelValue("syslog", lvl)
+	})
+}
+
+func SyslogLevelValue(lvl *LevelValue) Option {
+	return optionFunc(func(c *config) {
+		c.syslogConfig.Level = lvl
+	})
+}
+
+// SyslogSampling limits repeated entries sent to syslog.
+func SyslogSampling(opts SamplingOptions) Option {
+	return optionFunc(func(c *config) {
+		c.syslogConfig.Sampling = &opts
+	})
+}
+
+// Journald adds an output writing entries with their fields to the systemd
+// journal, see JournaldAvailable.
+func Journald(opts JournaldOptions) Option {
+	return optionFunc(func(c *config) {
+		c.journaldConfig.Enabled = true
+		c.journaldConfig.Options = opts
+	})
+}
+
+func JournaldLevel(lvl Level) Option {
+	return optionFunc(func(c *config) {
+		c.journaldConfig.Level = NewNamedLevelValue("journald", lvl)
+	})
+}
+
+func JournaldLevelValue(lvl *LevelValue) Option {
+	return optionFunc(func(c *config) {
+		c.journaldConfig.Level = lvl
+	})
+}
+
+// JournaldSampling limits repeated entries written to the journal.
+func JournaldSampling(opts SamplingOptions) Option {
+	return optionFunc(func(c *config) {
+		c.journaldConfig.Sampling = &opts
+	})
+}
+
+// NoConsole disables the console output, e.g. when entries are only written
+// to Graylog or to custom handlers.
+func NoConsole() Option {
+	return optionFunc(func(c *config) {
+		c.consoleConfig.disabled = true
+	})
+}
+
+// WithHandler adds a handler next to the console, GELF and file handlers.
+// Use HandlerWithLevel to control the level of the handler with a LevelValue.
+func WithHandler(h Handler) Option {
+	return optionFunc(func(c *config) {
+		c.handlers = append(c.handlers, h)
+	})
+}
+
+// WithHook adds a hook called before entries are written. Hooks are called in
+// the order they are added.
+func WithHook(h Hook) Option {
+	return optionFunc(func(c *config) {
+		c.hooks = append(c.hooks, h)
+	})
+}
+
+// Redact masks sensitive fields and values before entries are written to
+// any output.
+func Redact(opts RedactOptions) Option {
+	return optionFunc(func(c *config) {
+		c.redactor = newRedactor(opts)
+	})
+}
+
+// WithExitFunc replaces os.Exit called after Fatal entries, e.g. to
+// intercept the exit in tests.
+func WithExitFunc(exit func(code int)) Option {
+	return optionFunc(func(c *config) {
+		c.exitFunc = exit
+	})
+}
+
+// WithPanicFunc replaces the panic raised after Panic entries.
+func WithPanicFunc(panicFunc func(msg string)) Option {
+	return optionFunc(func(c *config) {
+		c.panicFunc = panicFunc
+	})
+}
+
+// WithFlushTimeout bounds how long Close, Panic and Fatal wait for entries
+// queued by asynchronous handlers, five seconds by default. Entries still queued then
+// are dropped.
+func WithFlushTimeout(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		if d > 0 {
+			c.flushTimeout = d
+		}
+	})
+}
+
+// NoPanicAndExit restores the legacy behavior where Panic and Fatal only log
+// the entry.
+func NoPanicAndExit() Option {
+	return optionFunc(func(c *config) {
+		c.noPanicAndExit = true
 	})
 }
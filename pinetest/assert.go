@@ -0,0 +1,67 @@
+package pinetest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-pckg/pine"
+)
+
+// AssertT is the subset of testing.TB used by the assertions.
+type AssertT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertLogged asserts that an entry at lvl with the message and all fields
+// was recorded.
+func AssertLogged(t AssertT, logs *ObservedLogs, lvl pine.Level, msg string, fields ...pine.Field) bool {
+	t.Helper()
+	matches := logs.FilterMessage(msg).Filter(func(e LoggedEntry) bool {
+		return e.Level == lvl
+	})
+	for _, f := range fields {
+		matches = matches.FilterField(f)
+	}
+	if matches.Len() == 0 {
+		t.Errorf("no %s entry %q with fields %s, logged:\n%s", lvl, msg, formatFields(fields), formatLogs(logs))
+		return false
+	}
+	return true
+}
+
+// AssertNotLogged asserts that no entry with the message was recorded.
+func AssertNotLogged(t AssertT, logs *ObservedLogs, msg string) bool {
+	t.Helper()
+	if logs.FilterMessage(msg).Len() > 0 {
+		t.Errorf("unexpected entry %q, logged:\n%s", msg, formatLogs(logs))
+		return false
+	}
+	return true
+}
+
+// AssertEmpty asserts that no entry was recorded.
+func AssertEmpty(t AssertT, logs *ObservedLogs) bool {
+	t.Helper()
+	if logs.Len() > 0 {
+		t.Errorf("unexpected entries, logged:\n%s", formatLogs(logs))
+		return false
+	}
+	return true
+}
+
+func formatLogs(logs *ObservedLogs) string {
+	sb := strings.Builder{}
+	for _, e := range logs.All() {
+		sb.WriteString(fmt.Sprintf("\t%s %q %s\n", e.Level, e.Message, formatFields(e.Fields)))
+	}
+	return sb.String()
+}
+
+func formatFields(fields []pine.Field) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%s=%v", f.Key(), f.Value())
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
@@ -0,0 +1,53 @@
+package pinetest
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/go-pckg/pine"
+)
+
+// TestingT is the subset of testing.TB used by NewLogger.
+type TestingT interface {
+	Log(args ...interface{})
+	Cleanup(func())
+}
+
+// NewLogger returns a logger writing through t.Log, so the output belongs to
+// the test and is only shown when it fails or with go test -v. Entries logged
+// after the test finished are dropped. Fatal and panic entries do not exit
+// or panic.
+func NewLogger(t TestingT, opts ...pine.Option) *pine.Logger {
+	w := &testWriter{t: t}
+	t.Cleanup(w.close)
+	opts = append([]pine.Option{
+		pine.Output(w),
+		pine.ErrOutput(w),
+		pine.NoColors(),
+		pine.WithLevel(pine.TraceLevel),
+		pine.NoPanicAndExit(),
+	}, opts...)
+	return pine.New(opts...)
+}
+
+type testWriter struct {
+	mu   sync.Mutex
+	t    TestingT
+	done bool
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		// t.Log adds the line break itself
+		w.t.Log(string(bytes.TrimRight(p, "\n")))
+	}
+	return len(p), nil
+}
+
+func (w *testWriter) close() {
+	w.mu.Lock()
+	w.done = true
+	w.mu.Unlock()
+}
@@ -0,0 +1,202 @@
+// Package pinetest provides loggers for tests: an observer recording entries
+// as structured values and a logger writing through testing.TB.
+package pinetest
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-pckg/pine"
+)
+
+// LoggedEntry is an entry recorded by the observer.
+type LoggedEntry struct {
+	Level      pine.Level
+	Time       time.Time
+	LoggerName string
+	Message    string
+	// Caller is set when the logger reports callers.
+	Caller *pine.Caller
+	// Stacks are the stack traces of the logged errors, one for every
+	// branch of joined errors.
+	Stacks []string
+	// Fields are the entry fields followed by the logger fields.
+	Fields []pine.Field
+}
+
+// ContextMap returns the values of the fields by key, see pine.Field.Value.
+// The first field with a key wins.
+func (e LoggedEntry) ContextMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(e.Fields))
+	for _, f := range e.Fields {
+		if _, ok := m[f.Key()]; !ok {
+			m[f.Key()] = f.Value()
+		}
+	}
+	return m
+}
+
+// Field returns the first field with the key.
+func (e LoggedEntry) Field(key string) (pine.Field, bool) {
+	for _, f := range e.Fields {
+		if f.Key() == key {
+			return f, true
+		}
+	}
+	return pine.Field{}, false
+}
+
+// ObservedLogs is a concurrency safe collection of recorded entries.
+type ObservedLogs struct {
+	mu   sync.RWMutex
+	logs []LoggedEntry
+}
+
+// NewObserver returns a handler recording the entries up to lvl and the logs
+// it records to.
+func NewObserver(lvl pine.Level) (pine.Handler, *ObservedLogs) {
+	logs := &ObservedLogs{}
+	return &observer{level: lvl, logs: logs}, logs
+}
+
+// NewObservedLogger returns a logger recording the entries up to lvl. Fatal
+// and panic entries are recorded without exiting or panicking.
+func NewObservedLogger(lvl pine.Level, opts ...pine.Option) (*pine.Logger, *ObservedLogs) {
+	h, logs := NewObserver(lvl)
+	opts = append([]pine.Option{pine.NoConsole(), pine.WithHandler(h), pine.NoPanicAndExit()}, opts...)
+	return pine.New(opts...), logs
+}
+
+// Len returns the number of recorded entries.
+func (o *ObservedLogs) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.logs)
+}
+
+// All returns a copy of the recorded entries.
+func (o *ObservedLogs) All() []LoggedEntry {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	logs := make([]LoggedEntry, len(o.logs))
+	copy(logs, o.logs)
+	return logs
+}
+
+// TakeAll returns the recorded entries and removes them.
+func (o *ObservedLogs) TakeAll() []LoggedEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	logs := o.logs
+	o.logs = nil
+	return logs
+}
+
+// Messages returns the messages of the recorded entries.
+func (o *ObservedLogs) Messages() []string {
+	all := o.All()
+	msgs := make([]string, len(all))
+	for i := range all {
+		msgs[i] = all[i].Message
+	}
+	return msgs
+}
+
+// FilterMessage returns the entries with the message.
+func (o *ObservedLogs) FilterMessage(msg string) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		return e.Message == msg
+	})
+}
+
+// FilterMessageSnippet returns the entries whose message contains snippet.
+func (o *ObservedLogs) FilterMessageSnippet(snippet string) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		return strings.Contains(e.Message, snippet)
+	})
+}
+
+// FilterField returns the entries with a field of the same key and value.
+func (o *ObservedLogs) FilterField(field pine.Field) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		for _, f := range e.Fields {
+			if f.Key() == field.Key() && reflect.DeepEqual(f.Value(), field.Value()) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FilterFieldKey returns the entries with a field of the key.
+func (o *ObservedLogs) FilterFieldKey(key string) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		_, ok := e.Field(key)
+		return ok
+	})
+}
+
+// FilterLevelAtLeast returns the entries at lvl or more severe, e.g. warnings
+// and errors for WarnLevel.
+func (o *ObservedLogs) FilterLevelAtLeast(lvl pine.Level) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		return e.Level <= lvl
+	})
+}
+
+// FilterLoggerName returns the entries of the named logger.
+func (o *ObservedLogs) FilterLoggerName(name string) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		return e.LoggerName == name
+	})
+}
+
+// Filter returns the entries matching fn.
+func (o *ObservedLogs) Filter(fn func(LoggedEntry) bool) *ObservedLogs {
+	filtered := &ObservedLogs{}
+	for _, e := range o.All() {
+		if fn(e) {
+			filtered.logs = append(filtered.logs, e)
+		}
+	}
+	return filtered
+}
+
+func (o *ObservedLogs) add(e LoggedEntry) {
+	o.mu.Lock()
+	o.logs = append(o.logs, e)
+	o.mu.Unlock()
+}
+
+type observer struct {
+	level pine.Level
+	logs  *ObservedLogs
+}
+
+func (h *observer) Enabled(lvl pine.Level) bool {
+	return h.level >= lvl
+}
+
+func (h *observer) Write(rec pine.Record) error {
+	var stacks []string
+	for _, st := range rec.Stacks() {
+		stacks = append(stacks, st.String())
+	}
+
+	h.logs.add(LoggedEntry{
+		Level:      rec.Level(),
+		Time:       rec.Time(),
+		LoggerName: rec.LoggerName(),
+		Message:    rec.Message(),
+		Caller:     rec.Caller(),
+		Stacks:     stacks,
+		Fields:     rec.Fields(),
+	})
+	return nil
+}
+
+func (h *observer) Close() error {
+	return nil
+}
@@ -0,0 +1,136 @@
+package pinetest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-pckg/pine"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	lgr, logs := NewObservedLogger(pine.InfoLevel, pine.Fields(pine.String("service", "api")))
+
+	lgr.Debug("ignored")
+	lgr.Info("started", pine.Int("port", 8080))
+	lgr.Named("db").Warn("slow query", pine.String("table", "orders"))
+	lgr.Error("failed", pine.Err(errors.New("boom")))
+
+	require.Equal(t, 3, logs.Len())
+	entries := logs.All()
+	assert.Equal(t, pine.InfoLevel, entries[0].Level)
+	assert.Equal(t, "started", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{"port": int64(8080), "service": "api"}, entries[0].ContextMap())
+	assert.Equal(t, "db", entries[1].LoggerName)
+
+	assert.Equal(t, []string{"started"}, logs.FilterField(pine.Int("port", 8080)).Messages())
+	assert.Equal(t, []string{"slow query", "failed"}, logs.FilterLevelAtLeast(pine.WarnLevel).Messages())
+	assert.Equal(t, []string{"slow query"}, logs.FilterMessageSnippet("slow").Messages())
+	assert.Equal(t, []string{"slow query"}, logs.FilterLoggerName("db").Messages())
+	assert.Equal(t, 3, logs.FilterFieldKey("service").Len())
+	assert.Equal(t, 1, logs.FilterMessage("failed").Len())
+
+	assert.Len(t, logs.TakeAll(), 3)
+	assert.Equal(t, 0, logs.Len())
+}
+
+func TestObserver_FieldsAreCopied(t *testing.T) {
+	lgr, logs := NewObservedLogger(pine.InfoLevel)
+
+	fields := []pine.Field{pine.String("a", "1")}
+	lgr.Info("first", fields...)
+	fields[0] = pine.String("a", "2")
+
+	assert.Equal(t, "1", logs.All()[0].ContextMap()["a"])
+}
+
+func TestObserver_FatalDoesNotExit(t *testing.T) {
+	lgr, logs := NewObservedLogger(pine.InfoLevel)
+
+	lgr.Panic("panicked")
+	lgr.Fatal("exited")
+
+	assert.Equal(t, []string{"panicked", "exited"}, logs.Messages())
+}
+
+type joinedErrors []error
+
+func (e joinedErrors) Error() string   { return "joined" }
+func (e joinedErrors) Unwrap() []error { return e }
+
+func TestObserver_Stacks(t *testing.T) {
+	lgr, logs := NewObservedLogger(pine.InfoLevel)
+
+	lgr.Error("failed", pine.Err(joinedErrors{pkgerrors.New("a"), pkgerrors.New("b")}))
+
+	stacks := logs.All()[0].Stacks
+	require.Len(t, stacks, 2)
+	assert.Contains(t, stacks[0], "TestObserver_Stacks")
+	assert.Contains(t, stacks[1], "TestObserver_Stacks")
+}
+
+type fakeT struct {
+	errors []string
+	logs   []string
+	clean  []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func (t *fakeT) Cleanup(fn func()) {
+	t.clean = append(t.clean, fn)
+}
+
+func TestAssertions(t *testing.T) {
+	lgr, logs := NewObservedLogger(pine.InfoLevel)
+	ft := &fakeT{}
+
+	assert.True(t, AssertEmpty(ft, logs))
+	lgr.Info("started", pine.Int("port", 8080))
+
+	assert.True(t, AssertLogged(ft, logs, pine.InfoLevel, "started", pine.Int("port", 8080)))
+	assert.True(t, AssertNotLogged(ft, logs, "stopped"))
+	assert.Empty(t, ft.errors)
+
+	assert.False(t, AssertLogged(ft, logs, pine.InfoLevel, "started", pine.Int("port", 80)))
+	assert.False(t, AssertLogged(ft, logs, pine.WarnLevel, "started"))
+	assert.False(t, AssertNotLogged(ft, logs, "started"))
+	assert.False(t, AssertEmpty(ft, logs))
+	require.Len(t, ft.errors, 4)
+	assert.Equal(t, "no info entry \"started\" with fields [port=80], logged:\n\tinfo \"started\" [port=8080]\n", ft.errors[0])
+}
+
+type testClock struct{}
+
+func (testClock) Now() time.Time {
+	return time.Date(2022, 8, 10, 21, 29, 59, 0, time.UTC)
+}
+
+func TestNewLogger(t *testing.T) {
+	ft := &fakeT{}
+	lgr := NewLogger(ft, pine.WithClock(testClock{}))
+
+	lgr.Info("hello", pine.Int("i", 1))
+	lgr.Trace("details")
+	for _, fn := range ft.clean {
+		fn()
+	}
+	lgr.Info("after the test")
+
+	assert.Equal(t, []string{
+		"2022-08-10T21:29:59.000Z INF hello i=1",
+		"2022-08-10T21:29:59.000Z TRC details",
+	}, ft.logs)
+}
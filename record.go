@@ -0,0 +1,55 @@
+package pine
+
+import "time"
+
+// Record is the read-only view of an entry passed to handlers. It must not
+// be retained after Handler.Write returns, the entry behind it is reused.
+type Record struct {
+	e *Entry
+}
+
+// Level returns the level of the entry.
+func (r Record) Level() Level {
+	return r.e.level
+}
+
+// Time returns the time the entry was logged at.
+func (r Record) Time() time.Time {
+	return r.e.time
+}
+
+// LoggerName returns the name of the logger the entry was logged with.
+func (r Record) LoggerName() string {
+	return r.e.name
+}
+
+// Message returns the formatted message of the entry.
+func (r Record) Message() string {
+	return r.e.message
+}
+
+// Caller returns the location the entry was logged from, or nil when it is
+// unknown.
+func (r Record) Caller() *Caller {
+	if r.e.caller == nil {
+		return nil
+	}
+	c := *r.e.caller
+	return &c
+}
+
+// Stack returns the stack trace of the logged error, or nil.
+func (r Record) Stack() Stack {
+	return r.e.Stack()
+}
+
+// Stacks returns the stack traces of the logged errors, one for every branch
+// of joined errors.
+func (r Record) Stacks() []Stack {
+	return append([]Stack(nil), r.e.stacks...)
+}
+
+// Fields returns a copy of the entry fields followed by the logger fields.
+func (r Record) Fields() []Field {
+	return append([]Field(nil), r.e.fields...)
+}
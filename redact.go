@@ -0,0 +1,349 @@
+package pine
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Masker replaces a sensitive value.
+type Masker func(value string) string
+
+// MaskFull replaces the whole value with [REDACTED].
+func MaskFull() Masker {
+	return func(string) string {
+		return "[REDACTED]"
+	}
+}
+
+// MaskPartial replaces all but the last visible characters with '*'.
+func MaskPartial(visible int) Masker {
+	return func(value string) string {
+		runes := []rune(value)
+		n := len(runes) - visible
+		if n < 0 {
+			n = len(runes)
+		}
+		for i := 0; i < n; i++ {
+			runes[i] = '*'
+		}
+		return string(runes)
+	}
+}
+
+// MaskHMAC replaces the value with a keyed hash, so equal values can be
+// correlated without revealing them.
+func MaskHMAC(key []byte) Masker {
+	return func(value string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:16]
+	}
+}
+
+// Detector finds sensitive values in strings.
+type Detector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Validate confirms a match, e.g. with a checksum. Optional.
+	Validate func(match string) bool
+	// Mask overrides the masker of the redaction for this detector. Optional.
+	Mask Masker
+}
+
+var (
+	cardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	jwtPattern        = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	awsKeyPattern     = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+)
+
+// CardNumberDetector finds payment card numbers passing the Luhn check.
+func CardNumberDetector() Detector {
+	return Detector{Name: "card_number", Pattern: cardNumberPattern, Validate: luhnValid}
+}
+
+func EmailDetector() Detector {
+	return Detector{Name: "email", Pattern: emailPattern}
+}
+
+func JWTDetector() Detector {
+	return Detector{Name: "jwt", Pattern: jwtPattern}
+}
+
+// AWSKeyDetector finds AWS access key ids.
+func AWSKeyDetector() Detector {
+	return Detector{Name: "aws_key", Pattern: awsKeyPattern}
+}
+
+// DefaultDetectors returns all built-in detectors.
+func DefaultDetectors() []Detector {
+	return []Detector{CardNumberDetector(), EmailDetector(), JWTDetector(), AWSKeyDetector()}
+}
+
+func luhnValid(number string) bool {
+	var digits []int
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// RedactOptions configures the redaction of entries before they are written.
+type RedactOptions struct {
+	// Keys are case-insensitive field names or glob patterns like "*token*"
+	// whose values are masked. They also apply to keys inside Json fields,
+	// objects and arrays.
+	Keys []string
+	// Detectors find sensitive values in the message and in string, error,
+	// Json and Interface fields and in objects and arrays.
+	Detectors []Detector
+	// Mask replaces the values. Defaults to MaskFull.
+	Mask Masker
+}
+
+type redactor struct {
+	keys      []string
+	detectors []Detector
+	mask      Masker
+}
+
+func newRedactor(opts RedactOptions) *redactor {
+	r := &redactor{detectors: opts.Detectors, mask: opts.Mask}
+	if r.mask == nil {
+		r.mask = MaskFull()
+	}
+	for _, k := range opts.Keys {
+		r.keys = append(r.keys, strings.ToLower(k))
+	}
+	return r
+}
+
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactEntry masks the message and the fields of the entry.
+func (r *redactor) redactEntry(e *Entry) {
+	e.message, _ = r.redactString(e.message)
+
+	var fields []Field
+	for i := range e.fields {
+		f, changed := r.redactField(e.fields[i])
+		f.detail = e.fields[i].detail
+		if changed && fields == nil {
+			// copy on write, the fields may belong to the caller
+			fields = make([]Field, len(e.fields))
+			copy(fields, e.fields)
+		}
+		if fields != nil {
+			fields[i] = f
+		}
+	}
+	if fields != nil {
+		e.fields = fields
+	}
+}
+
+func (r *redactor) redactField(f Field) (Field, bool) {
+	if r.matchKey(f.key) {
+		_, value, err := getStringValue(f)
+		if err != nil {
+			value = ""
+		}
+		return String(f.key, r.mask(value)), true
+	}
+
+	switch f.tp {
+	case stringType:
+		if s, ok := r.redactString(f.string); ok {
+			return String(f.key, s), true
+		}
+	case errorType:
+		if f.err == nil {
+			return f, false
+		}
+		if s, ok := r.redactString(f.err.Error()); ok {
+			return Field{tp: errorType, key: f.key, err: errors.New(s)}, true
+		}
+	case jsonType:
+		if v, ok := r.redactJSON(f.value); ok {
+			return Json(f.key, v), true
+		}
+	case interfaceType:
+		if s, ok := r.redactString(fmt.Sprint(f.value)); ok {
+			return String(f.key, s), true
+		}
+	case objectType, arrayType:
+		v, err := marshalField(f)
+		if err != nil {
+			return f, false
+		}
+		if v, ok := r.redactValue(v); ok {
+			// the masked value replaces the source, which holds the secrets
+			f.value = marshaled{src: v, tree: v}
+			return f, true
+		}
+	}
+	return f, false
+}
+
+func (r *redactor) redactString(s string) (string, bool) {
+	changed := false
+	for _, d := range r.detectors {
+		mask := d.Mask
+		if mask == nil {
+			mask = r.mask
+		}
+		s = d.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if d.Validate != nil && !d.Validate(match) {
+				return match
+			}
+			changed = true
+			return mask(match)
+		})
+	}
+	return s, changed
+}
+
+func (r *redactor) redactJSON(value interface{}) (interface{}, bool) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return value, false
+	}
+	var tree interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return value, false
+	}
+	return r.redactTree(tree)
+}
+
+func (r *redactor) redactTree(v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		changed := false
+		for k, val := range t {
+			if r.matchKey(k) {
+				t[k] = r.mask(jsonText(val))
+				changed = true
+				continue
+			}
+			if nv, ok := r.redactTree(val); ok {
+				t[k] = nv
+				changed = true
+			}
+		}
+		return t, changed
+	case []interface{}:
+		changed := false
+		for i := range t {
+			if nv, ok := r.redactTree(t[i]); ok {
+				t[i] = nv
+				changed = true
+			}
+		}
+		return t, changed
+	case string:
+		return r.redactString(t)
+	default:
+		return v, false
+	}
+}
+
+// redactValue masks the leaves of a marshaled object or array, keeping its
+// structure. v is copied when a leaf changes.
+func (r *redactor) redactValue(v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case objectValue:
+		var out objectValue
+		for i, e := range t {
+			var nv interface{}
+			var changed bool
+			if r.matchKey(e.key) {
+				nv, changed = r.mask(valueText(e.value)), true
+			} else {
+				nv, changed = r.redactValue(e.value)
+			}
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = append(objectValue{}, t...)
+			}
+			out[i].value = nv
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case arrayValue:
+		var out arrayValue
+		for i := range t {
+			nv, changed := r.redactValue(t[i])
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = append(arrayValue{}, t...)
+			}
+			out[i] = nv
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case string:
+		return r.redactString(t)
+	default:
+		return v, false
+	}
+}
+
+// valueText renders a marshaled value for masking.
+func valueText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b := &bytes.Buffer{}
+	appendJSONValue(b, v)
+	return b.String()
+}
+
+func jsonText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
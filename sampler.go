@@ -0,0 +1,220 @@
+package pine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SamplingRule lets the First entries with the same level and message
+// through in every tick and then every Thereafter-th one. A zero Thereafter
+// drops all entries after the first ones.
+type SamplingRule struct {
+	First      int
+	Thereafter int
+}
+
+// SamplingOptions configures the sampling of a handler. Only levels with a
+// rule are sampled.
+type SamplingOptions struct {
+	Tick   time.Duration
+	Levels map[Level]SamplingRule
+}
+
+// SampleAll returns options applying the same rule to every level.
+func SampleAll(tick time.Duration, first, thereafter int) SamplingOptions {
+	opts := SamplingOptions{Tick: tick, Levels: map[Level]SamplingRule{}}
+	for lvl := PanicLevel; lvl <= TraceLevel; lvl++ {
+		opts.Levels[lvl] = SamplingRule{First: first, Thereafter: thereafter}
+	}
+	return opts
+}
+
+// SampledHandler returns a handler which limits repeated entries passed to h.
+// When a tick ends, the number of suppressed entries is reported by a timer,
+// or with the next entry if it arrives first, and when the handler is closed.
+// Errors writing the reports of the timer are returned by the next Write or
+// Close.
+func SampledHandler(h Handler, opts SamplingOptions) Handler {
+	if opts.Tick <= 0 {
+		opts.Tick = time.Second
+	}
+	return &sampledHandler{
+		Handler:  h,
+		opts:     opts,
+		counters: map[sampleKey]*sampleCounter{},
+	}
+}
+
+func withSampling(h Handler, opts *SamplingOptions) Handler {
+	if opts == nil {
+		return h
+	}
+	return SampledHandler(h, *opts)
+}
+
+type sampleKey struct {
+	name    string
+	level   Level
+	message string
+}
+
+type sampleCounter struct {
+	seen       int
+	suppressed int
+}
+
+type sampledHandler struct {
+	Handler
+	opts SamplingOptions
+
+	mu       sync.Mutex
+	window   time.Time
+	counters map[sampleKey]*sampleCounter
+	// timer reports the suppressed entries when the window ends
+	timer *time.Timer
+	err   error
+}
+
+func (h *sampledHandler) Write(rec Record) error {
+	ent := rec.e
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.err; err != nil {
+		h.err = nil
+		return err
+	}
+
+	window := ent.time.Truncate(h.opts.Tick)
+	if !window.Equal(h.window) {
+		h.stopTimer()
+		if err := h.flush(ent.time); err != nil {
+			return err
+		}
+		h.window = window
+	}
+
+	rule, ok := h.opts.Levels[ent.level]
+	if !ok {
+		return h.Handler.Write(rec)
+	}
+
+	key := sampleKey{name: ent.name, level: ent.level, message: ent.message}
+	c := h.counters[key]
+	if c == nil {
+		c = &sampleCounter{}
+		h.counters[key] = c
+	}
+	c.seen++
+
+	if c.seen <= rule.First || (rule.Thereafter > 0 && (c.seen-rule.First)%rule.Thereafter == 0) {
+		return h.Handler.Write(rec)
+	}
+	c.suppressed++
+	if h.timer == nil {
+		// entry times may come from a custom clock, so only the remaining
+		// time of the window is taken from them
+		h.timer = time.AfterFunc(window.Add(h.opts.Tick).Sub(ent.time), func() {
+			h.flushWindow(window)
+		})
+	}
+	return nil
+}
+
+// flushWindow reports the suppressed entries of window unless an entry of a
+// later window already did.
+func (h *sampledHandler) flushWindow(window time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.timer == nil || !window.Equal(h.window) {
+		return
+	}
+	h.timer = nil
+	if err := h.flush(window.Add(h.opts.Tick)); err != nil && h.err == nil {
+		h.err = err
+	}
+	h.window = time.Time{}
+}
+
+func (h *sampledHandler) stopTimer() {
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+}
+
+// flush reports the suppressed entries of the closed window.
+func (h *sampledHandler) flush(now time.Time) error {
+	counters := h.counters
+	h.counters = map[sampleKey]*sampleCounter{}
+
+	for key, c := range counters {
+		if c.suppressed == 0 {
+			continue
+		}
+		summary := &Entry{
+			name:    key.name,
+			level:   key.level,
+			time:    now,
+			message: fmt.Sprintf("suppressed %d similar messages", c.suppressed),
+			fields:  []Field{String("sampled_message", key.message)},
+		}
+		if err := h.Handler.Write(Record{e: summary}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *sampledHandler) levelValue() *LevelValue {
+	if lh, ok := h.Handler.(leveler); ok {
+		return lh.levelValue()
+	}
+	return nil
+}
+
+func (h *sampledHandler) Sync(ctx context.Context) error {
+	if s, ok := h.Handler.(Syncer); ok {
+		return s.Sync(ctx)
+	}
+	return nil
+}
+
+func (h *sampledHandler) stats() Stats {
+	if s, ok := h.Handler.(statsProvider); ok {
+		return s.stats()
+	}
+	return Stats{}
+}
+
+func (h *sampledHandler) Close() error {
+	return h.close(h.Handler.Close)
+}
+
+func (h *sampledHandler) closeContext(ctx context.Context) error {
+	if c, ok := h.Handler.(contextCloser); ok {
+		return h.close(func() error {
+			return c.closeContext(ctx)
+		})
+	}
+	return h.Close()
+}
+
+// close reports the suppressed entries and closes the wrapped handler.
+func (h *sampledHandler) close(closeHandler func() error) error {
+	h.mu.Lock()
+	h.stopTimer()
+	err := h.flush(h.window.Add(h.opts.Tick))
+	if err == nil {
+		err = h.err
+	}
+	h.err = nil
+	h.mu.Unlock()
+
+	if cerr := closeHandler(); cerr != nil {
+		return cerr
+	}
+	return err
+}
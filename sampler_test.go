@@ -0,0 +1,85 @@
+package pine
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Sampling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	clock := newTestClock()
+	rec := &recordingHandler{}
+	lgr := New(NoColors(), Output(buf), WithClock(clock), WithLevel(TraceLevel),
+		Sampling(SampleAll(time.Second, 2, 3)), WithHandler(rec))
+
+	for i := 0; i < 10; i++ {
+		lgr.Warn("hot")
+	}
+	lgr.Info("hot")
+
+	assert.Equal(t, ""+
+		"2022-08-10T21:29:59.123Z WRN hot\n"+
+		"2022-08-10T21:29:59.123Z WRN hot\n"+
+		"2022-08-10T21:29:59.123Z WRN hot\n"+
+		"2022-08-10T21:29:59.123Z WRN hot\n"+
+		"2022-08-10T21:29:59.123Z INF hot\n", buf.String())
+	assert.Equal(t, 11, len(rec.entries))
+
+	buf.Reset()
+	clock.date = clock.date.Add(time.Second)
+	lgr.Warn("cold")
+	assert.Equal(t, ""+
+		"2022-08-10T21:30:00.123Z WRN suppressed 6 similar messages sampled_message=hot\n"+
+		"2022-08-10T21:30:00.123Z WRN cold\n", buf.String())
+
+	buf.Reset()
+	lgr.Close()
+	assert.Equal(t, "", buf.String())
+}
+
+func TestSampledHandler_Levels(t *testing.T) {
+	rec := &recordingHandler{}
+	h := SampledHandler(rec, SamplingOptions{
+		Tick:   time.Minute,
+		Levels: map[Level]SamplingRule{DebugLevel: {First: 1}},
+	})
+	lgr := New(Output(&bytes.Buffer{}), WithClock(newTestClock()), WithHandler(h))
+
+	for i := 0; i < 3; i++ {
+		lgr.Debug("debug")
+		lgr.Error("error")
+	}
+	assert.Equal(t, 4, len(rec.entries))
+
+	lgr.Close()
+	assert.Equal(t, 5, len(rec.entries))
+	assert.Equal(t, "suppressed 2 similar messages", rec.entries[4].message)
+	assert.Equal(t, "debug", rec.entries[4].fields["sampled_message"])
+	assert.True(t, rec.closed)
+}
+
+func TestSampledHandler_ReportsWhenTickEnds(t *testing.T) {
+	rec := &recordingHandler{}
+	h := SampledHandler(rec, SampleAll(50*time.Millisecond, 1, 0)).(*sampledHandler)
+	lgr := New(NoConsole(), WithHandler(h))
+
+	for i := 0; i < 3; i++ {
+		lgr.Info("hot")
+	}
+
+	// no further entries arrive, the timer reports the suppressed ones
+	assert.Eventually(t, func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(rec.entries) == 2
+	}, time.Second, 5*time.Millisecond)
+	h.mu.Lock()
+	assert.Equal(t, "suppressed 2 similar messages", rec.entries[1].message)
+	h.mu.Unlock()
+
+	lgr.Close()
+	assert.Equal(t, 2, len(rec.entries))
+}
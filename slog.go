@@ -0,0 +1,222 @@
+//go:build go1.21
+// +build go1.21
+
+package pine
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"runtime"
+)
+
+// SlogHandler is a slog.Handler writing records through a pine Logger.
+type SlogHandler struct {
+	logger *Logger
+	prefix string
+}
+
+// NewSlogHandler returns a slog.Handler which sends records to the handlers
+// of the given logger. Entries are timestamped by the logger clock.
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return h.logger.isLevelEnabled(levelFromSlog(lvl))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := h.logger.newEntry()
+	defer entryPool.Put(e)
+
+	e.level = levelFromSlog(r.Level)
+	e.message = r.Message
+	e.caller = nil
+	if r.PC != 0 {
+		fs := runtime.CallersFrames([]uintptr{r.PC})
+		f, _ := fs.Next()
+		e.caller = &Caller{File: shortFile(f.File), Line: f.Line, pc: r.PC}
+	}
+
+	fields := make([]Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, h.prefix, a)
+		return true
+	})
+
+	h.logger.write(e, h.logger.contextFields(ctx, e.level, fields))
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(attrs))
+	for i := range attrs {
+		fields = appendSlogAttr(fields, h.prefix, attrs[i])
+	}
+	return &SlogHandler{logger: h.logger.With(fields...), prefix: h.prefix}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, prefix: h.prefix + name + "."}
+}
+
+func appendSlogAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	v := a.Value.Resolve()
+	if a.Key == "" && v.Kind() != slog.KindGroup {
+		return fields
+	}
+
+	key := prefix + a.Key
+	switch v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = key + "."
+		}
+		for i := range attrs {
+			fields = appendSlogAttr(fields, groupPrefix, attrs[i])
+		}
+		return fields
+	case slog.KindString:
+		return append(fields, String(key, v.String()))
+	case slog.KindInt64:
+		return append(fields, Int64(key, v.Int64()))
+	case slog.KindUint64:
+		if u := v.Uint64(); u <= math.MaxInt64 {
+			return append(fields, Int64(key, int64(u)))
+		}
+		return append(fields, String(key, v.String()))
+	case slog.KindFloat64:
+		return append(fields, Float64(key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, Bool(key, v.Bool()))
+	case slog.KindTime:
+		return append(fields, Time(key, v.Time()))
+	case slog.KindDuration:
+		return append(fields, String(key, v.Duration().String()))
+	default:
+		if err, ok := v.Any().(error); ok {
+			return append(fields, Field{tp: errorType, key: key, err: err})
+		}
+		return append(fields, Interface(key, v.Any()))
+	}
+}
+
+func levelFromSlog(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelDebug:
+		return TraceLevel
+	case lvl < slog.LevelInfo:
+		return DebugLevel
+	case lvl < slog.LevelWarn:
+		return InfoLevel
+	case lvl < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+func slogLevel(lvl Level) slog.Level {
+	switch lvl {
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case PanicLevel:
+		return slog.LevelError + 4
+	case FatalLevel:
+		return slog.LevelError + 8
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithSlogHandler adds a handler forwarding every entry to h.
+func WithSlogHandler(h slog.Handler) Option {
+	return optionFunc(func(c *config) {
+		c.handlers = append(c.handlers, &slogBackend{handler: h})
+	})
+}
+
+// NewSlogLogger creates a Logger on top of the given slog.Handler. Console
+// output is disabled, so h receives every entry.
+func NewSlogLogger(h slog.Handler, options ...Option) *Logger {
+	opts := append([]Option{}, options...)
+	opts = append(opts, WithSlogHandler(h), NoConsole())
+	return New(opts...)
+}
+
+type slogBackend struct {
+	handler slog.Handler
+}
+
+func (h *slogBackend) Enabled(lvl Level) bool {
+	return h.handler.Enabled(context.Background(), slogLevel(lvl))
+}
+
+func (h *slogBackend) Write(rec Record) error {
+	ent := rec.e
+	fields := ent.fields
+	var pc uintptr
+	if ent.caller != nil {
+		pc = ent.caller.pc
+	}
+	r := slog.NewRecord(ent.time, slogLevel(ent.level), ent.message, pc)
+	for i := range fields {
+		ok, attr, err := slogAttr(fields[i])
+		if err != nil {
+			return err
+		}
+		if ok {
+			r.AddAttrs(attr)
+		}
+	}
+	for i, st := range ent.stacks {
+		r.AddAttrs(slog.String(stackKey("stack", i), flattenStack(st)))
+	}
+	return h.handler.Handle(context.Background(), r)
+}
+
+func (h *slogBackend) Close() error {
+	return nil
+}
+
+func slogAttr(field Field) (bool, slog.Attr, error) {
+	switch field.tp {
+	case stringType:
+		return true, slog.String(field.key, field.string), nil
+	case intType, int8Type, int16Type, int32Type, int64Type:
+		return true, slog.Int64(field.key, field.int64), nil
+	case boolType:
+		return true, slog.Bool(field.key, field.int64 == 1), nil
+	case float32Type, float64Type:
+		return true, slog.Float64(field.key, field.float64), nil
+	case errorType:
+		if field.err == nil {
+			return false, slog.Attr{}, nil
+		}
+		return true, slog.Any(field.key, field.err), nil
+	case jsonType, interfaceType, timeType:
+		return true, slog.Any(field.key, field.value), nil
+	default:
+		ok, value, err := getStringValue(field)
+		return ok, slog.String(field.key, value), err
+	}
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
@@ -0,0 +1,57 @@
+//go:build go1.21
+// +build go1.21
+
+package pine
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()), WithLevel(InfoLevel), WithStackTraceLevel(DisabledLevel))
+	slgr := slog.New(NewSlogHandler(lgr))
+
+	t.Run("attrs", func(tt *testing.T) {
+		slgr.Info("hello", "i", 1, "b", true, slog.Group("req", slog.String("id", "42")), slog.Any("err", errors.New("oops")))
+		assert.Equal(tt, "2022-08-10T21:29:59.123Z INF hello b=true err=oops i=1 req.id=42\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("level", func(tt *testing.T) {
+		slgr.Debug("hidden")
+		assert.Equal(tt, "", buf.String())
+		slgr.Warn("shown")
+		assert.Equal(tt, "2022-08-10T21:29:59.123Z WRN shown\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("with attrs and group", func(tt *testing.T) {
+		slgr.With("svc", "api").WithGroup("http").With("method", "GET").Info("hello", "status", 200)
+		assert.Equal(tt, "2022-08-10T21:29:59.123Z INF hello http.method=GET http.status=200 svc=api\n", buf.String())
+		buf.Reset()
+	})
+}
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	lgr := NewSlogLogger(h, WithClock(newTestClock()))
+
+	lgr.Trace("hidden")
+	lgr.Info("hello", Int("i", 1), String("s", "a b"), Bool("ok", true))
+	assert.Equal(t, "level=INFO msg=hello i=1 s=\"a b\" ok=true\n", buf.String())
+}
@@ -0,0 +1,120 @@
+package pine
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type joinedError []error
+
+func (e joinedError) Error() string {
+	return "joined"
+}
+
+func (e joinedError) Unwrap() []error {
+	return e
+}
+
+type callersError struct {
+	pcs []uintptr
+}
+
+func (e callersError) Error() string {
+	return "callers"
+}
+
+func (e callersError) Callers() []uintptr {
+	return e.pcs
+}
+
+func newCallersError() error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	return callersError{pcs: pcs[:n]}
+}
+
+type customError struct{}
+
+func (customError) Error() string {
+	return "custom"
+}
+
+func logStacks(t *testing.T, err error, opts ...Option) []Stack {
+	t.Helper()
+	rec := &stackRecorder{}
+	lgr := New(append([]Option{Output(&bytes.Buffer{}), WithHandler(rec)}, opts...)...)
+	lgr.Error("hello", Err(err))
+	return rec.stacks
+}
+
+type stackRecorder struct {
+	stacks []Stack
+}
+
+func (h *stackRecorder) Enabled(lvl Level) bool {
+	return true
+}
+
+func (h *stackRecorder) Write(rec Record) error {
+	h.stacks = rec.Stacks()
+	return nil
+}
+
+func (h *stackRecorder) Close() error {
+	return nil
+}
+
+func TestStack_Wrapped(t *testing.T) {
+	stacks := logStacks(t, fmt.Errorf("wrapped: %w", outer()))
+	require.Len(t, stacks, 1)
+	assert.True(t, strings.HasPrefix(flattenStack(stacks[0]), "inner() at stacktrace_test.go:10 <- outer() at stacktrace_test.go:6 <- "))
+}
+
+func TestStack_Joined(t *testing.T) {
+	stacks := logStacks(t, joinedError{errors.New("first"), stderrors.New("plain"), newCallersError()})
+	require.Len(t, stacks, 2)
+	assert.Equal(t, "github.com/go-pckg/pine.TestStack_Joined", stacks[0][0].Function)
+	assert.Equal(t, "github.com/go-pckg/pine.newCallersError", stacks[1][0].Function)
+}
+
+func TestStack_Extractor(t *testing.T) {
+	extractor := func(err error) Stack {
+		if _, ok := err.(customError); ok {
+			return Stack{{Function: "pkg.custom", File: "/src/custom.go", Line: 3}}
+		}
+		return nil
+	}
+
+	stacks := logStacks(t, fmt.Errorf("wrapped: %w", customError{}), WithStackExtractor(extractor))
+	require.Len(t, stacks, 1)
+	assert.Equal(t, "custom() at custom.go:3", flattenStack(stacks[0]))
+	assert.Equal(t, "\npkg.custom\n\t/src/custom.go:3", stacks[0].String())
+}
+
+func TestStack_Capture(t *testing.T) {
+	assert.Nil(t, logStacks(t, stderrors.New("plain")))
+
+	stacks := logStacks(t, stderrors.New("plain"), CaptureStack())
+	require.Len(t, stacks, 1)
+	assert.Equal(t, "github.com/go-pckg/pine.logStacks", stacks[0][0].Function)
+}
+
+func TestStack_Multiple(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(Output(buf), WithClock(newTestClock()), WithFormat(JSONFormat))
+	lgr.Error("hello", Err(joinedError{errors.New("a"), errors.New("b")}))
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Contains(t, out["stack"], "TestStack_Multiple() at stack_test.go")
+	assert.Contains(t, out["stack.1"], "TestStack_Multiple() at stack_test.go")
+}
@@ -2,81 +2,148 @@ package pine
 
 import (
 	"fmt"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/pkg/errors"
 )
 
-type state struct {
-	b []byte
+// Frame is a single frame of a stack trace.
+type Frame struct {
+	// Function is the fully qualified function name.
+	Function string
+	File     string
+	Line     int
 }
 
-func (s *state) Write(b []byte) (n int, err error) {
-	s.b = b
-	return len(b), nil
-}
+// Stack is a stack trace, innermost frame first.
+type Stack []Frame
 
-func (s *state) Width() (wid int, ok bool) {
-	return 0, false
-}
+// StackExtractor returns the stack trace carried by err itself, without
+// unwrapping it, or nil.
+type StackExtractor func(err error) Stack
 
-func (s *state) Precision() (prec int, ok bool) {
-	return 0, false
+// PkgErrorsStack extracts stack traces of github.com/pkg/errors and of
+// libraries using the same StackTrace method, like cockroachdb/errors.
+func PkgErrorsStack(err error) Stack {
+	st, ok := err.(interface{ StackTrace() errors.StackTrace })
+	if !ok {
+		return nil
+	}
+	trace := st.StackTrace()
+	pcs := make([]uintptr, len(trace))
+	for i := range trace {
+		pcs[i] = uintptr(trace[i])
+	}
+	return StackFromPCs(pcs)
 }
 
-func (s *state) Flag(c int) bool {
-	return false
+// CallersStack extracts stack traces of errors with a Callers method
+// returning program counters, like go-errors/errors.
+func CallersStack(err error) Stack {
+	c, ok := err.(interface{ Callers() []uintptr })
+	if !ok {
+		return nil
+	}
+	return StackFromPCs(c.Callers())
 }
 
-type stackTracer interface {
-	StackTrace() errors.StackTrace
-}
+var defaultStackExtractors = []StackExtractor{PkgErrorsStack, CallersStack}
 
-func getStackTracer(err error) stackTracer {
-	var sterr stackTracer
-	var ok bool
+// StackFromPCs resolves program counters as returned by runtime.Callers.
+func StackFromPCs(pcs []uintptr) Stack {
+	if len(pcs) == 0 {
+		return nil
+	}
+	st := make(Stack, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
 	for {
-		sterr, ok = err.(stackTracer)
-		if ok {
-			break
-		}
-		err = errors.Unwrap(err)
-		if err == nil {
+		fr, more := frames.Next()
+		st = append(st, Frame{Function: fr.Function, File: fr.File, Line: fr.Line})
+		if !more {
 			break
 		}
 	}
-	if !ok {
-		return nil
+	return st
+}
+
+// callersStack captures the stack of the current goroutine, skipping the
+// frames above the caller of callersStack.
+func callersStack(skip int) Stack {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	return StackFromPCs(pcs[:n])
+}
+
+// errorStacks walks the error tree depth-first and returns the outermost
+// stack of every branch. Branches of errors with an Unwrap() []error
+// method are visited in order.
+func errorStacks(err error, extractors []StackExtractor) []Stack {
+	var stacks []Stack
+	var walk func(err error)
+	walk = func(err error) {
+		for err != nil {
+			for _, extract := range extractors {
+				if st := extract(err); st != nil {
+					stacks = append(stacks, st)
+					return
+				}
+			}
+			switch u := err.(type) {
+			case interface{ Unwrap() []error }:
+				for _, e := range u.Unwrap() {
+					walk(e)
+				}
+				return
+			case interface{ Unwrap() error }:
+				err = u.Unwrap()
+			default:
+				return
+			}
+		}
 	}
-	return sterr
+	walk(err)
+	return stacks
 }
 
-func marshalStack(st errors.StackTrace) interface{} {
-	s := &state{}
-	out := make([]map[string]string, 0, len(st))
-	for _, fr := range st {
-		out = append(out, map[string]string{
-			"source": formatFrame(fr, s, 's'),
-			"line":   formatFrame(fr, s, 'd'),
-			"func":   formatFrame(fr, s, 'n'),
-		})
+// shortFunction returns the function name without the package path, e.g.
+// TestLogger.func1.
+func shortFunction(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
 	}
-	return out
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
 }
 
-func flattenStack(st errors.StackTrace) string {
+// flattenStack formats the stack on a single line.
+func flattenStack(st Stack) string {
 	sb := strings.Builder{}
-	for _, fr := range st {
-		sb.WriteString(fmt.Sprintf("%n() at %s:%d <- ", fr, fr, fr))
+	for i, fr := range st {
+		if i > 0 {
+			sb.WriteString(" <- ")
+		}
+		sb.WriteString(fmt.Sprintf("%s() at %s:%d", shortFunction(fr.Function), filepath.Base(fr.File), fr.Line))
 	}
-	return strings.TrimRight(sb.String(), " <- ")
+	return sb.String()
 }
 
-type frameFormatter interface {
-	Format(s fmt.State, verb rune)
+// String formats the stack with one function and location per line.
+func (st Stack) String() string {
+	sb := strings.Builder{}
+	for _, fr := range st {
+		sb.WriteString(fmt.Sprintf("\n%s\n\t%s:%d", fr.Function, fr.File, fr.Line))
+	}
+	return sb.String()
 }
 
-func formatFrame(f frameFormatter, s *state, verb rune) string {
-	f.Format(s, verb)
-	return string(s.b)
+// stackKey returns the field name for the i-th stack of an entry.
+func stackKey(key string, i int) string {
+	if i == 0 {
+		return key
+	}
+	return fmt.Sprintf("%s.%d", key, i)
 }
@@ -0,0 +1,138 @@
+package pine
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// StdLogOptions configures loggers of the standard library log package
+// writing to pine.
+type StdLogOptions struct {
+	// ParseLevel takes the level of a line from a prefix like "[ERROR]",
+	// "[WARN]" or "error:", which is removed from the message. Lines without
+	// such a prefix are logged at the default level.
+	ParseLevel bool
+}
+
+// NewStdLog returns a *log.Logger writing every line to l at lvl. Use it for
+// libraries accepting a *log.Logger.
+func NewStdLog(l *Logger, lvl Level) *log.Logger {
+	return NewStdLogWithOptions(l, lvl, StdLogOptions{})
+}
+
+// NewStdLogWithOptions is like NewStdLog and parses the lines according to
+// opts.
+func NewStdLogWithOptions(l *Logger, lvl Level, opts StdLogOptions) *log.Logger {
+	return log.New(newStdLogWriter(l, lvl, opts), "", log.Lshortfile)
+}
+
+// RedirectStdLog writes the output of the global log package to l at
+// InfoLevel. The returned func restores the previous output, flags and
+// prefix.
+func RedirectStdLog(l *Logger) func() {
+	return RedirectStdLogWithOptions(l, InfoLevel, StdLogOptions{})
+}
+
+// RedirectStdLogWithOptions is like RedirectStdLog with the default level lvl
+// and parses the lines according to opts.
+func RedirectStdLogWithOptions(l *Logger, lvl Level, opts StdLogOptions) func() {
+	flags, prefix, out := log.Flags(), log.Prefix(), log.Writer()
+	// the writer adds the time, the caller is taken from the line
+	log.SetFlags(log.Lshortfile)
+	log.SetPrefix("")
+	log.SetOutput(newStdLogWriter(l, lvl, opts))
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(out)
+	}
+}
+
+var stdLogLevels = map[string]Level{
+	"trace":   TraceLevel,
+	"debug":   DebugLevel,
+	"info":    InfoLevel,
+	"warn":    WarnLevel,
+	"warning": WarnLevel,
+	"err":     ErrorLevel,
+	"error":   ErrorLevel,
+	"fatal":   FatalLevel,
+	"panic":   PanicLevel,
+}
+
+// stdLogWriter logs the lines written by a *log.Logger. Fatal and panic lines
+// don't terminate, the log package exits or panics itself.
+type stdLogWriter struct {
+	logger     *Logger
+	level      Level
+	parseLevel bool
+}
+
+func newStdLogWriter(l *Logger, lvl Level, opts StdLogOptions) *stdLogWriter {
+	return &stdLogWriter{logger: l, level: lvl, parseLevel: opts.ParseLevel}
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	caller, msg := parseStdLogCaller(msg)
+	lvl := w.level
+	if w.parseLevel {
+		lvl, msg = parseStdLogLevel(msg, lvl)
+	}
+	if !w.logger.isLevelEnabled(lvl) {
+		return len(p), nil
+	}
+
+	e := w.logger.newEntry()
+	defer entryPool.Put(e)
+
+	e.level = lvl
+	e.message = msg
+	e.caller = caller
+	w.logger.write(e, nil)
+	return len(p), nil
+}
+
+// parseStdLogCaller removes the "file.go:12: " prefix written for
+// log.Lshortfile from msg.
+func parseStdLogCaller(msg string) (*Caller, string) {
+	i := strings.Index(msg, ": ")
+	if i < 0 {
+		return nil, msg
+	}
+	loc := msg[:i]
+	j := strings.LastIndexByte(loc, ':')
+	if j < 0 || !strings.HasSuffix(loc[:j], ".go") {
+		return nil, msg
+	}
+	line, err := strconv.Atoi(loc[j+1:])
+	if err != nil {
+		return nil, msg
+	}
+	return &Caller{File: loc[:j], Line: line}, msg[i+2:]
+}
+
+// parseStdLogLevel returns the level of a "[LEVEL] msg" or "level: msg" line
+// and the message without the prefix, or lvl and msg.
+func parseStdLogLevel(msg string, lvl Level) (Level, string) {
+	var name, rest string
+	if strings.HasPrefix(msg, "[") {
+		end := strings.IndexByte(msg, ']')
+		if end < 0 {
+			return lvl, msg
+		}
+		name, rest = msg[1:end], msg[end+1:]
+	} else {
+		end := strings.IndexByte(msg, ':')
+		if end < 0 {
+			return lvl, msg
+		}
+		name, rest = msg[:end], msg[end+1:]
+	}
+	parsed, ok := stdLogLevels[strings.ToLower(name)]
+	if !ok {
+		return lvl, msg
+	}
+	return parsed, strings.TrimLeft(rest, " \t")
+}
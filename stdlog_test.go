@@ -0,0 +1,81 @@
+package pine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStdLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()), AddCaller(), WithLevel(InfoLevel))
+
+	std := NewStdLog(lgr.Named("lib"), WarnLevel)
+	std.Print("[ERROR] connection reset")
+	_, _, line, _ := runtime.Caller(0)
+	NewStdLog(lgr, DebugLevel).Print("dropped")
+
+	assert.Equal(t, fmt.Sprintf("2022-08-10T21:29:59.123Z WRN stdlog_test.go:%d [lib] [ERROR] connection reset\n", line-1), buf.String())
+}
+
+func TestNewStdLog_ParseLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()))
+
+	std := NewStdLogWithOptions(lgr, InfoLevel, StdLogOptions{ParseLevel: true})
+	std.Print("[ERROR] connection reset")
+	std.Print("[warn]  retrying")
+	std.Print("DEBUG: details")
+	std.Print("[ERR] hashicorp style")
+	std.Print("[api] not a level")
+	std.Print("note: not a level")
+	std.Print("[ERROR")
+
+	assert.Equal(t, ""+
+		"2022-08-10T21:29:59.123Z ERR connection reset\n"+
+		"2022-08-10T21:29:59.123Z WRN retrying\n"+
+		"2022-08-10T21:29:59.123Z DBG details\n"+
+		"2022-08-10T21:29:59.123Z ERR hashicorp style\n"+
+		"2022-08-10T21:29:59.123Z INF [api] not a level\n"+
+		"2022-08-10T21:29:59.123Z INF note: not a level\n"+
+		"2022-08-10T21:29:59.123Z INF [ERROR\n", buf.String())
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	defer func(flags int, prefix string, out io.Writer) {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(out)
+	}(log.Flags(), log.Prefix(), log.Writer())
+
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFlags(log.LstdFlags)
+	log.SetPrefix("app: ")
+
+	buf := &bytes.Buffer{}
+	lgr := New(NoColors(), Output(buf), WithClock(newTestClock()))
+	restore := RedirectStdLogWithOptions(lgr, InfoLevel, StdLogOptions{ParseLevel: true})
+	log.Printf("[WARN] disk %d%% full", 91)
+	restore()
+
+	assert.Equal(t, "2022-08-10T21:29:59.123Z WRN disk 91% full\n", buf.String())
+	assert.Equal(t, out, log.Writer())
+	assert.Equal(t, log.LstdFlags, log.Flags())
+	assert.Equal(t, "app: ", log.Prefix())
+}
+
+func TestParseStdLogCaller(t *testing.T) {
+	caller, msg := parseStdLogCaller("main.go:12: hello: world")
+	assert.Equal(t, &Caller{File: "main.go", Line: 12}, caller)
+	assert.Equal(t, "hello: world", msg)
+
+	caller, msg = parseStdLogCaller("hello: world")
+	assert.Nil(t, caller)
+	assert.Equal(t, "hello: world", msg)
+}
@@ -0,0 +1,299 @@
+package pine
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// SyslogFormat selects the syslog message format.
+type SyslogFormat int8
+
+const (
+	// SyslogRFC5424 sends fields as STRUCTURED-DATA.
+	SyslogRFC5424 SyslogFormat = iota
+	// SyslogRFC3164 is the legacy BSD format; fields are appended to the
+	// message as key=value.
+	SyslogRFC3164
+)
+
+// SyslogFacility is the facility code of syslog messages.
+type SyslogFacility int
+
+const (
+	FacilityUser SyslogFacility = iota + 1
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+)
+
+const (
+	FacilityLocal0 SyslogFacility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// defaultSyslogSDID is the SD-ID of the fields, using the enterprise number
+// reserved for documentation by RFC 5612.
+const defaultSyslogSDID = "pine@32473"
+
+// SyslogOptions configures the syslog output.
+type SyslogOptions struct {
+	Format SyslogFormat
+	// Facility defaults to FacilityUser.
+	Facility SyslogFacility
+	// AppName defaults to the name of the executable.
+	AppName string
+	// Hostname defaults to os.Hostname.
+	Hostname string
+	// ProcID defaults to the process id.
+	ProcID string
+	// StructuredDataID is the SD-ID of the fields in RFC 5424 messages.
+	// Defaults to pine@32473.
+	StructuredDataID string
+	// TLSConfig is used by tls:// addresses and by addresses without a
+	// scheme.
+	TLSConfig *tls.Config
+}
+
+func (o SyslogOptions) withDefaults() SyslogOptions {
+	if o.Facility == 0 {
+		o.Facility = FacilityUser
+	}
+	if o.AppName == "" {
+		o.AppName = filepath.Base(os.Args[0])
+	}
+	if o.Hostname == "" {
+		o.Hostname, _ = os.Hostname()
+	}
+	if o.ProcID == "" {
+		o.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if o.StructuredDataID == "" {
+		o.StructuredDataID = defaultSyslogSDID
+	}
+	return o
+}
+
+type syslogHandler struct {
+	level   *LevelValue
+	encoder encoder
+	out     io.WriteCloser
+}
+
+func (h *syslogHandler) levelValue() *LevelValue {
+	return h.level
+}
+
+func (h *syslogHandler) Enabled(lvl Level) bool {
+	return h.level.GetLevel() >= lvl
+}
+
+func (h *syslogHandler) Write(rec Record) error {
+	ent := rec.e
+	buf, err := h.encoder.encodeEntry(ent, ent.fields)
+	if err != nil {
+		return err
+	}
+	_, err = h.out.Write(buf)
+	return err
+}
+
+func (h *syslogHandler) Close() error {
+	return h.out.Close()
+}
+
+// syslogEncoder renders an entry as a single syslog message without framing.
+type syslogEncoder struct {
+	opts    SyslogOptions
+	console consoleEncoder
+}
+
+func newSyslogEncoder(opts SyslogOptions, config encoderConfig) syslogEncoder {
+	config.UseColors = false
+	return syslogEncoder{opts: opts.withDefaults(), console: newConsoleEncoder(config)}
+}
+
+func (l syslogEncoder) encodeEntry(ent *Entry, fields []Field) ([]byte, error) {
+	sorted := make([]Field, len(fields), len(fields)+len(ent.stacks))
+	copy(sorted, fields)
+	if !l.console.DisableSorting {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].key < sorted[j].key
+		})
+	}
+	for i, st := range ent.stacks {
+		sorted = append(sorted, String(stackKey("stack", i), flattenStack(st)))
+	}
+	fields = sorted
+
+	if l.opts.Format == SyslogRFC3164 {
+		return l.encodeRFC3164(ent, fields)
+	}
+	return l.encodeRFC5424(ent, fields)
+}
+
+func (l syslogEncoder) priority(lvl Level) string {
+	return "<" + strconv.Itoa(int(l.opts.Facility)*8+int(gelfLevel(lvl))) + ">"
+}
+
+// encodeRFC5424 renders
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID key="value"] MSG.
+// The MSGID is the logger name.
+func (l syslogEncoder) encodeRFC5424(ent *Entry, fields []Field) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(l.priority(ent.level))
+	buf.WriteString("1 ")
+	buf.WriteString(ent.time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteByte(' ')
+	appendSyslogHeader(buf, l.opts.Hostname, 255)
+	buf.WriteByte(' ')
+	appendSyslogHeader(buf, l.opts.AppName, 48)
+	buf.WriteByte(' ')
+	appendSyslogHeader(buf, l.opts.ProcID, 128)
+	buf.WriteByte(' ')
+	appendSyslogHeader(buf, ent.name, 32)
+	buf.WriteByte(' ')
+
+	sd := &bytes.Buffer{}
+	if l.console.ReportCaller && ent.caller != nil {
+		appendSyslogParam(sd, "caller", ent.caller.File+":"+strconv.Itoa(ent.caller.Line))
+	}
+	for _, field := range fields {
+		if field.tp == objectType || field.tp == arrayType {
+			v, err := marshalField(field)
+			if err != nil {
+				return nil, err
+			}
+			flattenValue(field.key, ".", v, func(key string, leaf interface{}) {
+				appendSyslogParam(sd, key, leafString(leaf))
+			})
+			continue
+		}
+		ok, value, err := getStringValue(field)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			appendSyslogParam(sd, field.key, value)
+		}
+	}
+	if sd.Len() == 0 {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteByte('[')
+		buf.WriteString(l.opts.StructuredDataID)
+		buf.Write(sd.Bytes())
+		buf.WriteByte(']')
+	}
+
+	if ent.message != "" {
+		buf.WriteByte(' ')
+		if !isASCII(ent.message) {
+			// a BOM marks the message as UTF-8
+			buf.WriteString("\xef\xbb\xbf")
+		}
+		buf.WriteString(ent.message)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeRFC3164 renders <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG.
+func (l syslogEncoder) encodeRFC3164(ent *Entry, fields []Field) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(l.priority(ent.level))
+	buf.WriteString(ent.time.Format(time.Stamp))
+	buf.WriteByte(' ')
+	appendSyslogHeader(buf, l.opts.Hostname, 255)
+	buf.WriteByte(' ')
+	appendSyslogHeader(buf, l.opts.AppName, 32)
+	buf.WriteString("[" + l.opts.ProcID + "]: ")
+	if ent.name != "" {
+		buf.WriteString("[" + ent.name + "] ")
+	}
+	buf.WriteString(ent.message)
+
+	if l.console.ReportCaller && ent.caller != nil {
+		l.console.appendKeyValue(buf, "caller", ent.caller.File+":"+strconv.Itoa(ent.caller.Line), colorCyan)
+	}
+	for _, field := range fields {
+		if err := l.console.appendField(buf, field); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// appendSyslogHeader writes a header field of printable US-ASCII, or the
+// NILVALUE when it is empty.
+func appendSyslogHeader(b *bytes.Buffer, value string, maxLen int) {
+	if value == "" {
+		b.WriteByte('-')
+		return
+	}
+	n := 0
+	for i := 0; i < len(value) && n < maxLen; i++ {
+		c := value[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		b.WriteByte(c)
+		n++
+	}
+}
+
+// appendSyslogParam writes a PARAM-NAME="PARAM-VALUE" pair. Names are
+// limited to 32 printable characters except '=', ']', '"' and space.
+func appendSyslogParam(b *bytes.Buffer, key, value string) {
+	b.WriteByte(' ')
+	n := 0
+	for i := 0; i < len(key) && n < 32; i++ {
+		c := key[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b.WriteByte(c)
+		n++
+	}
+	if n == 0 {
+		b.WriteByte('_')
+	}
+	b.WriteString(`="`)
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
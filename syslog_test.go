@@ -0,0 +1,181 @@
+package pine
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSyslogOptions = SyslogOptions{AppName: "api", Hostname: "web-1", ProcID: "42"}
+
+func encodeSyslog(t *testing.T, opts SyslogOptions, ent *Entry, fields ...Field) string {
+	t.Helper()
+	b, err := newSyslogEncoder(opts, encoderConfig{}).encodeEntry(ent, fields)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestSyslogEncoder_RFC5424(t *testing.T) {
+	ent := &Entry{level: WarnLevel, time: testDate, message: "disk almost full", name: "storage"}
+	msg := encodeSyslog(t, testSyslogOptions, ent,
+		String("path", `C:\data [ssd]`), Int("percent", 91), Err(errors.New(`say "hi"`)), Strings("tags", []string{"a"}))
+	assert.Equal(t, `<12>1 2022-08-10T21:29:59.123456Z web-1 api 42 storage `+
+		`[pine@32473 error="say \"hi\"" path="C:\\data [ssd\]" percent="91" tags.0="a"] disk almost full`, msg)
+
+	opts := testSyslogOptions
+	opts.Facility = FacilityLocal3
+	opts.StructuredDataID = "acme@1234"
+	ent = &Entry{level: ErrorLevel, time: testDate, message: "größe"}
+	assert.Equal(t, "<155>1 2022-08-10T21:29:59.123456Z web-1 api 42 - - \xef\xbb\xbfgröße", encodeSyslog(t, opts, ent))
+}
+
+func TestSyslogEncoder_RFC3164(t *testing.T) {
+	opts := testSyslogOptions
+	opts.Format = SyslogRFC3164
+	opts.Facility = FacilityDaemon
+	ent := &Entry{level: InfoLevel, time: testDate, message: "started", name: "http"}
+	assert.Equal(t, `<30>Aug 10 21:29:59 web-1 api[42]: [http] started port=8080 scheme="https "`,
+		encodeSyslog(t, opts, ent, Int("port", 8080), String("scheme", "https ")))
+}
+
+func TestSyslogEncoder_Sanitize(t *testing.T) {
+	b := &bytes.Buffer{}
+	appendSyslogHeader(b, "my app\n", 48)
+	assert.Equal(t, "my_app_", b.String())
+
+	b.Reset()
+	appendSyslogParam(b, `a=b "c"]`, "")
+	assert.Equal(t, ` a_b__c__=""`, b.String())
+}
+
+func TestSyslog_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			// MSG-LEN SP SYSLOG-MSG
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(size[:len(size)-1])
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	lgr := New(NoColors(), Output(&bytes.Buffer{}), WithClock(newTestClock()),
+		Syslog("tcp://"+ln.Addr().String(), testSyslogOptions), SyslogLevel(InfoLevel))
+	lgr.Debug("hidden")
+	lgr.Info("first\nline")
+	lgr.Warn("second", Int("i", 2))
+	lgr.Close()
+
+	assert.Equal(t, "<14>1 2022-08-10T21:29:59.123456Z web-1 api 42 - - first\nline", <-received)
+	assert.Equal(t, `<12>1 2022-08-10T21:29:59.123456Z web-1 api 42 - [pine@32473 i="2"] second`, <-received)
+}
+
+func TestSyslog_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	lgr := New(NoColors(), Output(&bytes.Buffer{}), WithClock(newTestClock()),
+		Syslog("udp://"+conn.LocalAddr().String(), testSyslogOptions))
+	lgr.Error("failed")
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "<11>1 2022-08-10T21:29:59.123456Z web-1 api 42 - - failed", string(buf[:n]))
+}
+
+func TestSyslog_Sampling(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	lgr := New(NoColors(), Output(&bytes.Buffer{}), WithClock(newTestClock()),
+		Syslog("udp://"+conn.LocalAddr().String(), testSyslogOptions), SyslogSampling(SampleAll(time.Hour, 1, 0)))
+	for i := 0; i < 3; i++ {
+		lgr.Error("failed")
+	}
+	lgr.Close()
+
+	buf := make([]byte, 1024)
+	var received []string
+	for i := 0; i < 2; i++ {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		received = append(received, string(buf[:n]))
+	}
+	assert.Equal(t, []string{
+		"<11>1 2022-08-10T21:29:59.123456Z web-1 api 42 - - failed",
+		`<11>1 2022-08-10T22:00:00.000000Z web-1 api 42 - [pine@32473 sampled_message="failed"] suppressed 2 similar messages`,
+	}, received)
+}
+
+func TestSyslog_UnixDatagram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	conn, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	opts := testSyslogOptions
+	opts.Format = SyslogRFC3164
+	lgr := New(NoColors(), Output(&bytes.Buffer{}), WithClock(newTestClock()), Syslog("unix://"+path, opts))
+	lgr.Info("hello")
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "<14>Aug 10 21:29:59 web-1 api[42]: hello", string(buf[:n]))
+}
+
+func TestSyslog_UnixStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	ln, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	w := newSyslogWriter("unix://"+path, nil)
+	defer w.Close()
+	_, err = w.Write([]byte("<14>hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "<14>hello\n", <-received)
+}
@@ -0,0 +1,127 @@
+package pine
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-pckg/pine/gelf"
+)
+
+// localSyslogPaths are the sockets of the local syslog daemon on Linux, macOS
+// and BSD.
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// newSyslogWriter creates the transport for the address scheme:
+// unix:///dev/log, udp://host:514, tcp://host:601 or tls://host:6514. An empty
+// address uses the local syslog daemon. Addresses without a scheme use TCP,
+// or TLS when a TLS config is set.
+func newSyslogWriter(addr string, tlsConfig *tls.Config) io.WriteCloser {
+	switch {
+	case addr == "":
+		return &syslogConnWriter{dial: dialLocalSyslog(localSyslogPaths)}
+	case strings.HasPrefix(addr, "unix://"):
+		return &syslogConnWriter{dial: dialLocalSyslog([]string{strings.TrimPrefix(addr, "unix://")})}
+	case strings.HasPrefix(addr, "udp://"):
+		udpAddr := strings.TrimPrefix(addr, "udp://")
+		return &syslogConnWriter{dial: func() (net.Conn, bool, error) {
+			conn, err := net.Dial("udp", udpAddr)
+			return conn, false, err
+		}}
+	case strings.HasPrefix(addr, "tls://"):
+		return &syslogStreamWriter{out: gelf.NewTLSWriter(strings.TrimPrefix(addr, "tls://"), tlsConfig)}
+	case tlsConfig != nil:
+		return &syslogStreamWriter{out: gelf.NewTLSWriter(strings.TrimPrefix(addr, "tcp://"), tlsConfig)}
+	default:
+		return &syslogStreamWriter{out: gelf.NewTCPWriter(strings.TrimPrefix(addr, "tcp://"))}
+	}
+}
+
+// syslogStreamWriter frames messages with octet counting as described in
+// RFC 6587, e.g. "57 <14>1 ...". The TCP writer reconnects on errors.
+type syslogStreamWriter struct {
+	out io.WriteCloser
+}
+
+func (w *syslogStreamWriter) Write(p []byte) (int, error) {
+	msg := make([]byte, 0, len(p)+8)
+	msg = strconv.AppendInt(msg, int64(len(p)), 10)
+	msg = append(msg, ' ')
+	msg = append(msg, p...)
+	if _, err := w.out.Write(msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *syslogStreamWriter) Close() error {
+	return w.out.Close()
+}
+
+// dialLocalSyslog connects to the first path accepting datagrams or, like
+// older daemons, streams.
+func dialLocalSyslog(paths []string) func() (net.Conn, bool, error) {
+	return func() (net.Conn, bool, error) {
+		var errs []string
+		for _, path := range paths {
+			for _, network := range []string{"unixgram", "unix"} {
+				conn, err := net.Dial(network, path)
+				if err == nil {
+					return conn, network == "unix", nil
+				}
+				errs = append(errs, err.Error())
+			}
+		}
+		return nil, false, fmt.Errorf("no syslog socket: %s", strings.Join(errs, "; "))
+	}
+}
+
+// syslogConnWriter sends one message per datagram, or per line on stream
+// sockets. The connection is redialed once when a write fails.
+type syslogConnWriter struct {
+	dial func() (conn net.Conn, stream bool, err error)
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool
+}
+
+func (w *syslogConnWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	msg := p
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if w.conn, w.stream, err = w.dial(); err != nil {
+				w.conn = nil
+				return 0, err
+			}
+		}
+		if w.stream {
+			msg = append(p[:len(p):len(p)], '\n')
+		}
+		if _, err = w.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	return 0, err
+}
+
+func (w *syslogConnWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,93 @@
+package pine
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Panic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(Output(buf), WithClock(newTestClock()))
+
+	assert.PanicsWithValue(t, "boom 1", func() {
+		lgr.Panicf("boom %d", 1)
+	})
+	assert.Equal(t, "2022-08-10T21:29:59.123Z PNC boom 1\n", buf.String())
+	buf.Reset()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		lgr.WithFields(Int("i", 1)).Panicf("boom")
+	})
+	assert.Equal(t, "2022-08-10T21:29:59.123Z PNC boom i=1\n", buf.String())
+}
+
+func TestLogger_PanicDisabledLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(Output(buf), WithClock(newTestClock()), WithLevel(DisabledLevel))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		lgr.Panic("boom")
+	})
+	assert.Equal(t, "", buf.String())
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := &recordingHandler{}
+	var code int
+	lgr := New(Output(buf), WithClock(newTestClock()), WithHandler(h), WithExitFunc(func(c int) {
+		code = c
+	}))
+
+	lgr.Fatal("bye", String("reason", "test"))
+	assert.Equal(t, 1, code)
+	assert.True(t, h.closed)
+	assert.Equal(t, 1, len(h.entries))
+	assert.Equal(t, "2022-08-10T21:29:59.123Z FTL bye reason=test\n", buf.String())
+}
+
+func TestLogger_PanicFunc(t *testing.T) {
+	var got string
+	lgr := New(Output(&bytes.Buffer{}), WithPanicFunc(func(msg string) {
+		got = msg
+	}))
+	lgr.Panic("intercepted")
+	assert.Equal(t, "intercepted", got)
+}
+
+func TestLogger_NoPanicAndExit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := New(Output(buf), WithClock(newTestClock()), NoPanicAndExit(), WithExitFunc(func(int) {
+		t.Fatal("exit called")
+	}))
+
+	assert.NotPanics(t, func() {
+		lgr.Panic("boom")
+	})
+	lgr.Fatal("bye")
+	assert.Equal(t, "2022-08-10T21:29:59.123Z PNC boom\n2022-08-10T21:29:59.123Z FTL bye\n", buf.String())
+}
+
+func TestLogger_TerminateFlushTimeout(t *testing.T) {
+	out := newBlockingWriter()
+	defer close(out.release)
+	errOut := &bytes.Buffer{}
+	var panicked, exited bool
+	lgr := New(Output(&bytes.Buffer{}), ErrOutput(errOut), WithFlushTimeout(time.Millisecond*20),
+		WithPanicFunc(func(string) { panicked = true }), WithExitFunc(func(int) { exited = true }))
+	lgr.handlers = append(lgr.handlers, &gelfHandler{level: NewLevelValue(DisabledLevel), out: newAsyncWriter(out, nil, AsyncOptions{QueueSize: 10})})
+	_, _ = lgr.handlers[1].(*gelfHandler).out.Write([]byte("1"))
+	<-out.started
+
+	start := time.Now()
+	lgr.Panic("boom")
+	assert.True(t, panicked)
+	assert.Contains(t, errOut.String(), "sync error: context deadline exceeded")
+
+	lgr.Fatal("bye")
+	assert.True(t, exited)
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -0,0 +1,54 @@
+package pine
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// readEnvTLSConfig builds a TLS config from the PEM files referenced by the
+// <prefix>CA, <prefix>CERT and <prefix>KEY variables. It returns nil when
+// none of them is set.
+func readEnvTLSConfig(prefix string) (*tls.Config, error) {
+	caFile := readEnvOrDefaultString(prefix+"CA", "")
+	certFile := readEnvOrDefaultString(prefix+"CERT", "")
+	keyFile := readEnvOrDefaultString(prefix+"KEY", "")
+	serverName := readEnvOrDefaultString(prefix+"SERVER_NAME", "")
+	if caFile == "" && certFile == "" && keyFile == "" && serverName == "" {
+		return nil, nil
+	}
+	return newTLSConfig(caFile, certFile, keyFile, serverName)
+}
+
+func newTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("both client certificate and key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,170 @@
+package pine
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signer, signerCert := key, template
+	if parent != nil {
+		signer, signerCert = parent.key, parent.cert
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func newTestPKI(t *testing.T) (ca, server, client testCert) {
+	t.Helper()
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(time.Hour)
+
+	ca = newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pine test ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	server = newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "graylog.test"},
+		DNSNames:     []string{"graylog.test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	client = newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "pine client"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+	return ca, server, client
+}
+
+func TestLogger_GraylogTLS(t *testing.T) {
+	os.Clearenv()
+	ca, serverCert, _ := newTestPKI(t)
+
+	server := NewTLSServer("127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert.tlsCertificate(t)}})
+	require.NoError(t, server.Run())
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	lgr := New(Output(&bytes.Buffer{}), WithClock(newTestClock()), Graylog(server.Addr()),
+		GraylogTLS(&tls.Config{RootCAs: pool, ServerName: "graylog.test"}))
+	lgr.Info("hello")
+	time.Sleep(time.Millisecond * 10)
+	lgr.Close()
+	require.NoError(t, server.Close())
+
+	require.Equal(t, 1, len(server.messages))
+	assert.Contains(t, server.messages[0], `"short_message":"hello"`)
+}
+
+func TestLogger_GraylogMutualTLSFromEnv(t *testing.T) {
+	os.Clearenv()
+	ca, serverCert, clientCert := newTestPKI(t)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	server := NewTLSServer("127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate(t)},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	require.NoError(t, server.Run())
+
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, data, 0600))
+		return path
+	}
+	require.NoError(t, os.Setenv("PINE_GRAYLOG_TLS_CA", write("ca.pem", ca.certPEM)))
+	require.NoError(t, os.Setenv("PINE_GRAYLOG_TLS_CERT", write("client.pem", clientCert.certPEM)))
+	require.NoError(t, os.Setenv("PINE_GRAYLOG_TLS_KEY", write("client.key", clientCert.keyPEM)))
+	defer os.Clearenv()
+
+	lgr := New(Output(&bytes.Buffer{}), WithClock(newTestClock()), Graylog(server.Addr()))
+	lgr.Info("hello")
+	time.Sleep(time.Millisecond * 10)
+	lgr.Close()
+	require.NoError(t, server.Close())
+
+	require.Equal(t, 1, len(server.messages))
+	assert.Contains(t, server.messages[0], `"short_message":"hello"`)
+}
+
+func TestLogger_GraylogTLSConfigError(t *testing.T) {
+	os.Clearenv()
+	require.NoError(t, os.Setenv("PINE_GRAYLOG_TLS_CA", filepath.Join(t.TempDir(), "missing.pem")))
+	defer os.Clearenv()
+
+	errOut := &bytes.Buffer{}
+	lgr := New(Output(&bytes.Buffer{}), ErrOutput(errOut), Graylog("127.0.0.1:12201"))
+	assert.Equal(t, 1, len(lgr.handlers))
+	assert.Contains(t, errOut.String(), "gelf tls config error: read CA bundle")
+}
+
+func TestLogger_GraylogTLSWithUDP(t *testing.T) {
+	os.Clearenv()
+	errOut := &bytes.Buffer{}
+	lgr := New(Output(&bytes.Buffer{}), ErrOutput(errOut), Graylog("udp://127.0.0.1:12201"),
+		GraylogTLS(&tls.Config{}))
+	assert.Equal(t, 1, len(lgr.handlers))
+	assert.Contains(t, errOut.String(), "gelf tls config error: TLS is not supported by the UDP transport")
+}